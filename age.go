@@ -0,0 +1,33 @@
+package errors
+
+import (
+	"time"
+)
+
+// Now returns the current time wherever this package needs it: for the
+// creation time of new errors and for Age. It can be replaced in tests to
+// make those times deterministic. It can also be set with Configure.
+var Now = time.Now
+
+// LayerDeltas makes DebugString show how much time passed between the
+// creation of each *Error in a chain and of the *Error that wraps it, which
+// shows where the time went as an error propagated. It is off by default. It
+// can also be set with Configure.
+var LayerDeltas = false
+
+// Time returns when the error was created by New, Wrap or a similar
+// function. It is the zero time for errors created by ParsePanic or decoded
+// by UnmarshalJSON.
+func (err *Error) Time() time.Time {
+	return err.created
+}
+
+// Age returns how long ago the error was created, which shows how long it
+// took to propagate to where it is handled, e.g. through several retries. It
+// is 0 if the time the error was created is not known.
+func (err *Error) Age() time.Duration {
+	if err.created.IsZero() {
+		return 0
+	}
+	return config().Now().Sub(err.created)
+}
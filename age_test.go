@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNow(t *testing.T) {
+	defer func() { Now = time.Now }()
+
+	fixed := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	Now = func() time.Time { return fixed }
+
+	if err := Errorf("boom"); !err.Time().Equal(fixed) {
+		t.Errorf("Now was not used for the creation time: %v", err.Time())
+	}
+}
+
+func TestAge(t *testing.T) {
+	defer func() { Now = time.Now }()
+
+	clock := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	Now = func() time.Time { return clock }
+
+	err := New(io.EOF)
+	if !err.Time().Equal(clock) || err.Age() != 0 {
+		t.Errorf("Wrong creation time: %v", err.Time())
+	}
+
+	clock = clock.Add(3 * time.Second)
+	if err.Age() != 3*time.Second {
+		t.Errorf("Age did not increase: %v", err.Age())
+	}
+
+	if prefixed := WrapPrefix(err, "read", 0); !prefixed.Time().Equal(err.Time()) {
+		t.Errorf("WrapPrefix should keep the creation time")
+	}
+
+	if (&Error{Err: io.EOF}).Age() != 0 {
+		t.Errorf("Error without a creation time should have no age")
+	}
+}
+
+func TestLayerDeltas(t *testing.T) {
+	defer func() { Now, LayerDeltas = time.Now, false }()
+
+	clock := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	Now = func() time.Time { return clock }
+
+	inner := New(io.EOF)
+	clock = clock.Add(12 * time.Millisecond)
+	middle := WrapPrefixForce(inner, "read", 0)
+	clock = clock.Add(3 * time.Second)
+	outer := WrapPrefixForce(middle, "load", 0)
+
+	if strings.Contains(outer.DebugString(), "(+") {
+		t.Errorf("Deltas should be off by default:\n%s", outer.DebugString())
+	}
+
+	LayerDeltas = true
+	debug := outer.DebugString()
+	if !strings.Contains(debug, "wrapped at (+3s):\n") || !strings.Contains(debug, "wrapped at (+12ms):\n") || !strings.Contains(debug, "created at:\n") {
+		t.Errorf("Wrong deltas:\n%s", debug)
+	}
+
+	if strings.Index(debug, "(+3s)") > strings.Index(debug, "(+12ms)") {
+		t.Errorf("Outer delta should come first:\n%s", debug)
+	}
+}
@@ -0,0 +1,35 @@
+package errors
+
+import (
+	"sync/atomic"
+)
+
+var stackBoundary atomic.Value // string
+
+// SetStackBoundary makes rendered stacks stop at the first frame of the
+// function funcName, such as a request handler, leaving out the frames of
+// its callers, such as the HTTP server that called the handler. funcName is
+// either the package-qualified name, e.g. "example.com/app.(*Server).handle",
+// or the name within its package, e.g. "(*Server).handle". Stacks that do not
+// contain the function are rendered in full, and an empty funcName removes
+// the boundary. Like ExcludeGenerated, this only affects how stacks are
+// rendered; StackFrames still returns every frame.
+func SetStackBoundary(funcName string) {
+	stackBoundary.Store(funcName)
+}
+
+// truncateAtBoundary returns frames up to and including the first frame of
+// the function set with SetStackBoundary.
+func truncateAtBoundary(frames []StackFrame) []StackFrame {
+	boundary, _ := stackBoundary.Load().(string)
+	if boundary == "" {
+		return frames
+	}
+
+	for i, frame := range frames {
+		if frame.Name == boundary || frame.Package+"."+frame.Name == boundary {
+			return frames[:i+1]
+		}
+	}
+	return frames
+}
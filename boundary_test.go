@@ -0,0 +1,36 @@
+package errors
+
+import (
+	"io"
+	"testing"
+)
+
+func TestSetStackBoundary(t *testing.T) {
+	defer SetStackBoundary("")
+
+	err := &Error{Err: io.EOF, frames: []StackFrame{
+		{File: "/src/app/db.go", LineNumber: 40, Name: "query", Package: "example.com/app"},
+		{File: "/src/app/handler.go", LineNumber: 12, Name: "(*Server).handle", Package: "example.com/app"},
+		{File: "/go/src/net/http/server.go", LineNumber: 2084, Name: "HandlerFunc.ServeHTTP", Package: "net/http"},
+		{File: "/go/src/net/http/server.go", LineNumber: 1995, Name: "(*conn).serve", Package: "net/http"},
+	}}
+
+	SetStackBoundary("example.com/app.(*Server).handle")
+	if err.CompactStack() != "db.go:40:query\nhandler.go:12:(*Server).handle\n" {
+		t.Errorf("Stack was not truncated at the boundary:\n%s", err.CompactStack())
+	}
+
+	SetStackBoundary("(*Server).handle")
+	if err.CompactStack() != "db.go:40:query\nhandler.go:12:(*Server).handle\n" {
+		t.Errorf("Unqualified boundary did not match:\n%s", err.CompactStack())
+	}
+
+	SetStackBoundary("missing")
+	if len(renderedFrames(err.StackFrames())) != 4 {
+		t.Errorf("Stack without the boundary should be rendered in full")
+	}
+
+	if len(err.StackFrames()) != 4 {
+		t.Errorf("StackFrames should not be truncated")
+	}
+}
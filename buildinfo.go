@@ -0,0 +1,32 @@
+package errors
+
+import (
+	"runtime"
+	"sync"
+)
+
+var buildInfoOnce sync.Once
+var buildInfo map[string]string
+
+// BuildInfo returns information about how the program was built: the Go
+// version under "go", and where available the program's package path under
+// "path", the main module's path and version under "module" and "version",
+// and the version control settings recorded by the go command, such as
+// "vcs.revision". It is computed once and a new copy is returned each time.
+func BuildInfo() map[string]string {
+	buildInfoOnce.Do(func() {
+		buildInfo = map[string]string{"go": runtime.Version()}
+		for k, v := range readBuildInfo() {
+			buildInfo[k] = v
+		}
+		for k, v := range readBuildSettings() {
+			buildInfo[k] = v
+		}
+	})
+
+	info := make(map[string]string, len(buildInfo))
+	for k, v := range buildInfo {
+		info[k] = v
+	}
+	return info
+}
@@ -0,0 +1,37 @@
+//go:build go1.12
+// +build go1.12
+
+package errors
+
+import (
+	"runtime/debug"
+)
+
+// readMainModule returns the path of the main module of the program, or ""
+// if the program was built without module support.
+func readMainModule() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Path
+	}
+	return ""
+}
+
+// readBuildInfo returns the package and module information for BuildInfo.
+func readBuildInfo() map[string]string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	fields := map[string]string{}
+	if info.Path != "" {
+		fields["path"] = info.Path
+	}
+	if info.Main.Path != "" {
+		fields["module"] = info.Main.Path
+	}
+	if info.Main.Version != "" {
+		fields["version"] = info.Main.Version
+	}
+	return fields
+}
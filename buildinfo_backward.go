@@ -0,0 +1,16 @@
+//go:build !go1.12
+// +build !go1.12
+
+package errors
+
+// readMainModule returns the path of the main module of the program, which
+// is not available before go1.12.
+func readMainModule() string {
+	return ""
+}
+
+// readBuildInfo returns the package and module information for BuildInfo,
+// which is not available before go1.12.
+func readBuildInfo() map[string]string {
+	return nil
+}
@@ -0,0 +1,26 @@
+//go:build go1.18
+// +build go1.18
+
+package errors
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// readBuildSettings returns the version control settings recorded in the
+// binary for BuildInfo.
+func readBuildSettings() map[string]string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	settings := map[string]string{}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs" || strings.HasPrefix(setting.Key, "vcs.") {
+			settings[setting.Key] = setting.Value
+		}
+	}
+	return settings
+}
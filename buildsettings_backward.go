@@ -0,0 +1,10 @@
+//go:build !go1.18
+// +build !go1.18
+
+package errors
+
+// readBuildSettings returns the version control settings recorded in the
+// binary for BuildInfo, which are not available before go1.18.
+func readBuildSettings() map[string]string {
+	return nil
+}
@@ -0,0 +1,174 @@
+package errors
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+var wrappers struct {
+	sync.Mutex
+	packages atomic.Value // map[string]bool
+}
+
+// RegisterWrapper registers the path of a package that wraps the constructors
+// of this package, such as a logging helper. Stacktraces captured for new
+// errors start at the first frame outside of all registered packages, so the
+// wrapper does not need to pass a skip to every call. This is intended to be
+// called from the wrapper's init function.
+func RegisterWrapper(pkgPath string) {
+	wrappers.Lock()
+	defer wrappers.Unlock()
+
+	registered := map[string]bool{pkgPath: true}
+	if current, ok := wrappers.packages.Load().(map[string]bool); ok {
+		for pkg := range current {
+			registered[pkg] = true
+		}
+	}
+	wrappers.packages.Store(registered)
+}
+
+// capture returns the stack for a new error starting skip frames above the
+// caller of capture, taking RegisterWrapper, SiteSampleRate,
+// CaptureStackMinLevel and CollapseRecursion into account. If recursion was
+// collapsed, repeats holds the number of calls folded into each frame. If
+// CaptureStackMinLevel is above LevelError, the level of new errors, only the
+// call site is captured, which is also returned as site so that WithLevel can
+// expand it.
+func capture(cfg Config, skip int) (stack []uintptr, repeats []int, site uintptr) {
+	if cfg.CaptureStackMinLevel > LevelError {
+		stack = captureSite(cfg, 1+skip)
+		if len(stack) == 0 {
+			return nil, nil, 0
+		}
+		return stack, nil, stack[0]
+	}
+
+	stack, repeats = captureStack(cfg, 1+skip)
+	return stack, repeats, 0
+}
+
+// captureSite returns the frame skip frames above the caller of captureSite
+// that is outside the packages registered with RegisterWrapper.
+func captureSite(cfg Config, skip int) []uintptr {
+	registered, _ := wrappers.packages.Load().(map[string]bool)
+	if len(registered) == 0 {
+		return cfg.CaptureFunc(1+skip, 1)
+	}
+
+	// The call site is only known once the wrappers are trimmed, so the
+	// full stack has to be captured anyway.
+	stack := trimWrappers(cfg.CaptureFunc(1+skip, cfg.MaxStackDepth), registered)
+	if len(stack) > 1 {
+		stack = stack[:1]
+	}
+	return stack
+}
+
+// captureStack is capture without the CaptureStackMinLevel check, for errors
+// whose level is already known to be high enough.
+func captureStack(cfg Config, skip int) (stack []uintptr, repeats []int) {
+	registered, _ := wrappers.packages.Load().(map[string]bool)
+
+	if rate := cfg.SiteSampleRate; rate > 1 {
+		if len(registered) > 0 {
+			// The call site is only known once the wrappers are trimmed,
+			// so the full stack has to be captured anyway.
+			stack := trimWrappers(cfg.CaptureFunc(1+skip, cfg.MaxStackDepth), registered)
+			if len(stack) > 0 && !sampleSite(stack[0], rate) {
+				return stack[:1], nil
+			}
+			if !cfg.CollapseRecursion {
+				return stack, nil
+			}
+		} else {
+			site := cfg.CaptureFunc(1+skip, 1)
+			if len(site) == 1 && !sampleSite(site[0], rate) {
+				return site, nil
+			}
+		}
+	}
+
+	return captureFull(cfg, registered, 1+skip)
+}
+
+// captureFull returns the whole stack starting skip frames above the caller
+// of captureFull, without sampling.
+func captureFull(cfg Config, registered map[string]bool, skip int) (stack []uintptr, repeats []int) {
+	if cfg.CollapseRecursion {
+		stack := trimWrappers(cfg.CaptureFunc(1+skip, collapsedDepthFactor*cfg.MaxStackDepth), registered)
+		return collapseRecursion(stack, cfg.MaxStackDepth)
+	}
+
+	return trimWrappers(cfg.CaptureFunc(1+skip, cfg.MaxStackDepth), registered), nil
+}
+
+// CaptureStack returns the current stack as stack frames, for logging how the
+// program got somewhere without making an error. The skip parameter behaves
+// as for Wrap: 0 starts at the function that called CaptureStack, 1 at its
+// caller, etc. Like the stacktrace of a new error it has at most
+// MaxStackDepth frames and starts outside the packages registered with
+// RegisterWrapper. Recursion is collapsed if CollapseRecursion is set, but
+// the number of calls folded into each frame is not returned.
+func CaptureStack(skip int) []StackFrame {
+	registered, _ := wrappers.packages.Load().(map[string]bool)
+	stack, _ := captureFull(config(), registered, 1+skip)
+	return resolveFrames(stack)
+}
+
+// CollapseRecursion makes stacktraces keep a single frame for consecutive
+// calls of the same function, as made by recursive code, so that recursion
+// does not use up MaxStackDepth before the frames that called it are reached.
+// The number of calls folded into each frame is returned by Error.Repeats.
+// Up to collapsedDepthFactor times MaxStackDepth frames are examined. It is
+// off by default. It can also be set with Configure.
+var CollapseRecursion = false
+
+// collapsedDepthFactor is how many times MaxStackDepth frames are captured
+// when CollapseRecursion is set, before they are collapsed.
+const collapsedDepthFactor = 8
+
+// collapseRecursion folds consecutive frames of stack that are in the same
+// function into the first of them, keeping at most depth frames, and returns
+// the number of frames folded into each one.
+func collapseRecursion(stack []uintptr, depth int) ([]uintptr, []int) {
+	collapsed := make([]uintptr, 0, depth)
+	repeats := make([]int, 0, depth)
+
+	var previous string
+	for _, pc := range stack {
+		name := ""
+		if fn := runtime.FuncForPC(pc - 1); fn != nil {
+			name = fn.Name()
+		}
+		if len(collapsed) > 0 && name != "" && name == previous {
+			repeats[len(repeats)-1]++
+			continue
+		}
+		if len(collapsed) == depth {
+			break
+		}
+		collapsed = append(collapsed, pc)
+		repeats = append(repeats, 0)
+		previous = name
+	}
+
+	return collapsed, repeats
+}
+
+// trimWrappers removes the leading frames of stack that are in one of the
+// registered wrapper packages.
+func trimWrappers(stack []uintptr, registered map[string]bool) []uintptr {
+	for len(registered) > 0 && len(stack) > 0 {
+		fn := runtime.FuncForPC(stack[0] - 1)
+		if fn == nil {
+			break
+		}
+		if pkg, _ := packageAndName(fn); !registered[pkg] {
+			break
+		}
+		stack = stack[1:]
+	}
+	return stack
+}
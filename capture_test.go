@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"testing"
+)
+
+func TestRegisterWrapper(t *testing.T) {
+	defer wrappers.packages.Store(map[string]bool{})
+
+	frames := New("foo").StackFrames()
+	if frames[0].Package != "github.com/go-errors/errors" || frames[1].Package != "testing" {
+		t.Fatalf("Unexpected stack: %s, %s", frames[0].Package, frames[1].Package)
+	}
+
+	RegisterWrapper("github.com/go-errors/errors")
+	frames = New("foo").StackFrames()
+	if len(frames) != 2 || frames[0].Package != "testing" {
+		t.Errorf("Frames in the registered package were not trimmed: %v", frames)
+	}
+
+	RegisterWrapper("testing")
+	frames = Wrap("foo", 0).StackFrames()
+	if len(frames) != 1 || frames[0].Package != "runtime" {
+		t.Errorf("Frames in both registered packages were not trimmed: %v", frames)
+	}
+}
+
+func recurse(n int) *Error {
+	if n == 0 {
+		return New("bottom")
+	}
+	return recurse(n - 1)
+}
+
+func TestCollapseRecursion(t *testing.T) {
+	uncollapsed := recurse(100)
+	frames := uncollapsed.StackFrames()
+	if len(frames) != MaxStackDepth || frames[len(frames)-1].Name != "recurse" || uncollapsed.Repeats() != nil {
+		t.Fatalf("Recursion should use up the stack without collapsing: %d frames", len(frames))
+	}
+
+	CollapseRecursion = true
+	defer func() { CollapseRecursion = false }()
+
+	err := recurse(100)
+	frames, repeats := err.StackFrames(), err.Repeats()
+	if len(repeats) != len(frames) || frames[0].Name != "recurse" || repeats[0] != 100 {
+		t.Fatalf("Recursion should be collapsed into one frame: %s %v", frames[0].Name, repeats)
+	}
+
+	if len(frames) < 2 || frames[1].Name != "TestCollapseRecursion" || repeats[1] != 0 {
+		t.Errorf("Frames below the recursion should be kept: %v", frames)
+	}
+}
+
+func captureStackHelper() []StackFrame {
+	return CaptureStack(1)
+}
+
+func TestCaptureStack(t *testing.T) {
+	frames := CaptureStack(0)
+	if len(frames) < 2 || frames[0].Name != "TestCaptureStack" || frames[1].Package != "testing" {
+		t.Errorf("Stack should start at the caller of CaptureStack: %v", frames)
+	}
+
+	if frames := captureStackHelper(); frames[0].Name != "TestCaptureStack" {
+		t.Errorf("Skip should start the stack further up: %s", frames[0].Name)
+	}
+
+	defer func() { MaxStackDepth = 50 }()
+	MaxStackDepth = 1
+	if len(CaptureStack(0)) != 1 {
+		t.Errorf("Stack should respect MaxStackDepth")
+	}
+}
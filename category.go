@@ -0,0 +1,21 @@
+package errors
+
+// Categorized is implemented by errors that belong to a category defined by
+// the application, for example "validation" or "storage".
+type Categorized interface {
+	ErrorCategory() string
+}
+
+// Category returns the category of the outermost error in err's chain that
+// implements Categorized, or "" if there is none.
+func Category(err error) string {
+	var category string
+	walk(err, func(err error) bool {
+		if c, ok := err.(Categorized); ok {
+			category = c.ErrorCategory()
+			return false
+		}
+		return true
+	})
+	return category
+}
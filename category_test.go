@@ -0,0 +1,31 @@
+package errors
+
+import (
+	"io"
+	"testing"
+)
+
+type categorizedError struct {
+	category string
+	err      error
+}
+
+func (e categorizedError) Error() string         { return e.category + ": " + e.err.Error() }
+func (e categorizedError) Unwrap() error         { return e.err }
+func (e categorizedError) ErrorCategory() string { return e.category }
+
+func TestCategory(t *testing.T) {
+	err := WrapPrefix(New(categorizedError{"storage", io.EOF}), "load", 0)
+	if Category(err) != "storage" {
+		t.Errorf("Category was not found through two wraps: %q", Category(err))
+	}
+
+	outer := New(categorizedError{"validation", err})
+	if Category(outer) != "validation" {
+		t.Errorf("Outermost category should win: %q", Category(outer))
+	}
+
+	if Category(New(io.EOF)) != "" || Category(nil) != "" {
+		t.Errorf("Errors without a category should have none")
+	}
+}
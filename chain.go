@@ -0,0 +1,248 @@
+package errors
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// MaxUnwrapDepth bounds how deep Is, As and the other functions of this
+// package that examine an error's chain descend into it: errors that are
+// more than MaxUnwrapDepth levels below the error passed in are treated as if
+// they were not there. This caps the cost of pathologically deep chains and
+// guards against chains that contain a cycle. The default is 100. A value of
+// 0 or less removes the limit. It can also be set with Configure.
+var MaxUnwrapDepth = 100
+
+// walk calls fn for err and then for each error in its tree, following both
+// Unwrap() error and Unwrap() []error in the same depth-first order as the
+// standard library's errors.Is, down to MaxUnwrapDepth. It stops as soon as
+// fn returns false and reports whether the whole tree was visited.
+func walk(err error, fn func(error) bool) bool {
+	return walkDepth(err, fn, unwrapLimit())
+}
+
+// outermost returns the first *Error found by walk in err's chain for which
+// has returns true, or nil if there is none.
+func outermost(err error, has func(*Error) bool) *Error {
+	var found *Error
+	walk(err, func(err error) bool {
+		if err, ok := err.(*Error); ok && has(err) {
+			found = err
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// layersOf returns every *Error in err's chain in the order walk finds them,
+// so the outermost comes first.
+func layersOf(err error) []*Error {
+	var layers []*Error
+	walk(err, func(err error) bool {
+		if err, ok := err.(*Error); ok {
+			layers = append(layers, err)
+		}
+		return true
+	})
+	return layers
+}
+
+// walkDepth is walk with the number of levels left to visit, which is
+// unlimited if it is negative.
+func walkDepth(err error, fn func(error) bool, depth int) bool {
+	for ; err != nil; depth-- {
+		if depth == 0 {
+			return true
+		}
+
+		if !fn(err) {
+			return false
+		}
+
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, err := range x.Unwrap() {
+				if !walkDepth(err, fn, depth-1) {
+					return false
+				}
+			}
+			return true
+		default:
+			return true
+		}
+	}
+	return true
+}
+
+// unwrapLimit returns the number of levels of a chain to examine, starting
+// from a positive count; it is negative when there is no limit.
+func unwrapLimit() int {
+	if depth := config().MaxUnwrapDepth; depth > 0 {
+		return depth
+	}
+	return -1
+}
+
+// Chain returns the messages of every error in err's chain joined by ": ",
+// outermost first, without any stacktraces. Where an error's message already
+// ends with ": " and the message of the error it wraps, as with WrapPrefix or
+// fmt.Errorf("%w"), only the part it adds is used, so each message appears
+// once.
+func (err *Error) Chain() string {
+	var msgs []string
+
+	var link error = err
+	for depth := unwrapLimit(); link != nil && depth != 0; depth-- {
+		msg := link.Error()
+		next := Unwrap(link)
+
+		if next != nil {
+			inner := next.Error()
+			if msg == inner {
+				msg = ""
+			} else if strings.HasSuffix(msg, ": "+inner) {
+				msg = strings.TrimSuffix(msg, ": "+inner)
+			} else if strings.Contains(msg, inner) {
+				// The message already includes the rest of the chain.
+				next = nil
+			}
+		}
+
+		if msg != "" {
+			msgs = append(msgs, msg)
+		}
+		link = next
+	}
+
+	return strings.Join(msgs, ": ")
+}
+
+// RootMessage returns the message of the innermost error in err's chain,
+// without any of the prefixes added by the layers wrapping it. This is
+// useful for messages shown to users, where the breadcrumbs that Chain
+// returns are internal detail. Unwrapping stops at an error that joins
+// several others, whose message is then used.
+func (err *Error) RootMessage() string {
+	var root error = err
+	for depth := unwrapLimit() - 1; depth != 0; depth-- {
+		next := Unwrap(root)
+		if next == nil {
+			break
+		}
+		root = next
+	}
+
+	return root.Error()
+}
+
+// Unwrapped returns the first error in err's chain that is not an *Error,
+// removing the layers added by this package but keeping any wrapping below
+// them, such as by fmt.Errorf. This is for code that switches on the
+// concrete type of an error. Unlike RootMessage it stops at the first error
+// from elsewhere rather than unwrapping to the deepest one. It returns nil if
+// err is nil.
+func Unwrapped(err error) error {
+	for depth := unwrapLimit(); depth != 0; depth-- {
+		e, ok := err.(*Error)
+		if !ok {
+			break
+		}
+		err = e.Err
+	}
+	return err
+}
+
+// Depth returns the number of *Error values in err's chain, including err
+// itself. A high depth means the error was wrapped with a new stacktrace at
+// many layers, which is usually more than needed. WrapPrefix does not add to
+// the depth, as it replaces the error rather than wrapping it.
+func (err *Error) Depth() int {
+	return len(layersOf(err))
+}
+
+// MatchMessage reports whether the message of err or of any error in its
+// chain matches re. It is an escape hatch for errors from packages that offer
+// no type or sentinel to check with Is or As, and should not be used where
+// one is available, as messages can change. It returns false if err is nil.
+func MatchMessage(err error, re *regexp.Regexp) bool {
+	matched := false
+	walk(err, func(err error) bool {
+		matched = re.MatchString(err.Error())
+		return !matched
+	})
+	return matched
+}
+
+// Extract returns the first error in err's chain, starting with err itself,
+// that has the same dynamic type as sample. This is useful for retrieving a
+// domain error type from beneath several layers of wrapping when As cannot
+// be used conveniently, e.g. Extract(&NotFoundError{}).
+func (err *Error) Extract(sample error) (error, bool) {
+	sampleType := reflect.TypeOf(sample)
+	if sampleType == nil {
+		return nil, false
+	}
+
+	var found error
+	walk(err, func(err error) bool {
+		if reflect.TypeOf(err) == sampleType {
+			found = err
+			return false
+		}
+		return true
+	})
+
+	return found, found != nil
+}
+
+// AsWithStack behaves like As, but also returns the closest *Error above the
+// error that matched target in err's chain. This gives access to both the
+// matched error and the stacktrace of where it was wrapped. The *Error is nil
+// if no *Error wraps the match, and AsWithStack returns false if nothing in
+// the chain matches target.
+func AsWithStack(err error, target interface{}) (*Error, bool) {
+	val := reflect.ValueOf(target)
+	if target == nil || val.Kind() != reflect.Ptr || val.IsNil() {
+		panic("errors: target must be a non-nil pointer")
+	}
+
+	return asWithStack(err, val, nil, unwrapLimit())
+}
+
+func asWithStack(err error, target reflect.Value, enclosing *Error, depth int) (*Error, bool) {
+	targetType := target.Type().Elem()
+
+	for ; err != nil && depth != 0; depth-- {
+		if reflect.TypeOf(err).AssignableTo(targetType) {
+			target.Elem().Set(reflect.ValueOf(err))
+			return enclosing, true
+		}
+		if x, ok := err.(interface{ As(interface{}) bool }); ok && x.As(target.Interface()) {
+			return enclosing, true
+		}
+
+		if e, ok := err.(*Error); ok {
+			enclosing = e
+		}
+
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, err := range x.Unwrap() {
+				if e, ok := asWithStack(err, target, enclosing, depth-1); ok {
+					return e, true
+				}
+			}
+			return nil, false
+		default:
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
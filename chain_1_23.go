@@ -0,0 +1,19 @@
+//go:build go1.23
+// +build go1.23
+
+package errors
+
+import "iter"
+
+// Chain returns an iterator over err and every error in its tree, outermost
+// first, in the same depth-first order as Is, so that the chain can be
+// examined with a range loop:
+//
+//	for e := range errors.Chain(err) {
+//		...
+//	}
+func Chain(err error) iter.Seq[error] {
+	return func(yield func(error) bool) {
+		walk(err, yield)
+	}
+}
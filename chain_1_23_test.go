@@ -0,0 +1,39 @@
+//go:build go1.23
+// +build go1.23
+
+package errors
+
+import (
+	"io"
+	"testing"
+)
+
+func TestChainIterator(t *testing.T) {
+	inner := New(io.EOF)
+	middle := wrappingError{"read", inner}
+	outer := WrapPrefix(middle, "load", 0)
+
+	var links []error
+	for e := range Chain(outer) {
+		links = append(links, e)
+	}
+
+	if len(links) != 4 || links[0] != outer || links[1] != middle || links[2] != inner || links[3] != io.EOF {
+		t.Errorf("Wrong chain: %v", links)
+	}
+
+	links = nil
+	for e := range Chain(outer) {
+		links = append(links, e)
+		if e == middle {
+			break
+		}
+	}
+	if len(links) != 2 {
+		t.Errorf("Iteration did not stop: %v", links)
+	}
+
+	for e := range Chain(nil) {
+		t.Errorf("Nil error should have no chain: %v", e)
+	}
+}
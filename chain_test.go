@@ -0,0 +1,185 @@
+package errors
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type quietError struct{ err error }
+
+func (e quietError) Error() string { return "quiet" }
+func (e quietError) Unwrap() error { return e.err }
+
+type parenError struct{ err error }
+
+func (e parenError) Error() string { return "failed (" + e.err.Error() + ")" }
+func (e parenError) Unwrap() error { return e.err }
+
+// dialError ends with the message of its cause, but not after ": ".
+type dialError struct{ err error }
+
+func (e dialError) Error() string { return "dial tcp: i/o " + e.err.Error() }
+func (e dialError) Unwrap() error { return e.err }
+
+func TestChain(t *testing.T) {
+	err := WrapPrefix(WrapPrefix(io.EOF, "inner", 0), "outer", 0)
+	if err.Chain() != "outer: inner: EOF" {
+		t.Errorf("Wrong chain for WrapPrefix: %s", err.Chain())
+	}
+
+	err = New(wrappingError{"outer", wrappingError{"middle", io.EOF}})
+	if err.Chain() != "outer: middle: EOF" {
+		t.Errorf("Wrong chain for %%w style wrapping: %s", err.Chain())
+	}
+
+	err = New(quietError{New(io.EOF)})
+	if err.Chain() != "quiet: EOF" {
+		t.Errorf("Wrong chain for an error that hides its cause: %s", err.Chain())
+	}
+
+	err = New(parenError{io.EOF})
+	if err.Chain() != "failed (EOF)" {
+		t.Errorf("Wrong chain for an error that embeds its cause: %s", err.Chain())
+	}
+
+	err = New(dialError{timeoutError{true}})
+	if err.Chain() != "dial tcp: i/o timeout" {
+		t.Errorf("Wrong chain for an error that ends with its cause without a separator: %s", err.Chain())
+	}
+}
+
+func TestRootMessage(t *testing.T) {
+	err := WrapPrefix(WrapPrefix(WrapPrefix(io.EOF, "inner", 0), "middle", 0), "outer", 0)
+	if err.RootMessage() != "EOF" {
+		t.Errorf("Wrong root message: %s", err.RootMessage())
+	}
+
+	err = New(wrappingError{"lookup", New(io.ErrUnexpectedEOF)})
+	if err.RootMessage() != io.ErrUnexpectedEOF.Error() {
+		t.Errorf("Wrong root message for %%w style wrapping: %s", err.RootMessage())
+	}
+
+	if New("plain").RootMessage() != "plain" {
+		t.Errorf("Unwrapped error should use its own message")
+	}
+}
+
+func TestDepth(t *testing.T) {
+	err := New(wrappingError{"handler", New(wrappingError{"lookup", New(io.EOF)})})
+	if err.Depth() != 3 {
+		t.Errorf("Wrong depth: %d", err.Depth())
+	}
+
+	if WrapPrefix(New(io.EOF), "read", 0).Depth() != 1 {
+		t.Errorf("WrapPrefix should not add to the depth")
+	}
+}
+
+type lookupError struct {
+	Key string
+}
+
+func (e *lookupError) Error() string { return "missing " + e.Key }
+
+func TestExtract(t *testing.T) {
+	cause := &lookupError{Key: "user"}
+	err := WrapPrefix(New(wrappingError{"lookup", cause}), "handler", 0)
+
+	found, ok := err.Extract(&lookupError{})
+	if !ok || found != cause {
+		t.Errorf("Did not extract the buried error: %v", found)
+	}
+
+	if found, ok := err.Extract(quietError{}); ok || found != nil {
+		t.Errorf("Extracted an error of a type that is not in the chain")
+	}
+
+	if found, ok := err.Extract(nil); ok || found != nil {
+		t.Errorf("Extracted a nil sample")
+	}
+}
+
+func TestAsWithStack(t *testing.T) {
+	cause := &lookupError{Key: "user"}
+	inner := New(cause)
+	err := WrapPrefix(New(wrappingError{"lookup", inner}), "handler", 0)
+
+	var target *lookupError
+	wrapper, ok := AsWithStack(err, &target)
+	if !ok || target != cause {
+		t.Fatalf("Target was not found through two wraps")
+	}
+
+	if wrapper != inner {
+		t.Errorf("Wrong enclosing *Error: %v", wrapper)
+	}
+
+	var missing quietError
+	if wrapper, ok := AsWithStack(err, &missing); ok || wrapper != nil {
+		t.Errorf("Found a target that is not in the chain")
+	}
+
+	target = nil
+	if wrapper, ok := AsWithStack(cause, &target); !ok || wrapper != nil || target != cause {
+		t.Errorf("Unwrapped target should have no enclosing *Error")
+	}
+
+	target = nil
+	joined := Join(io.EOF, New(cause))
+	if wrapper, ok := AsWithStack(New(joined), &target); !ok || wrapper == nil || wrapper.Err != cause {
+		t.Errorf("Enclosing *Error was not found in a joined branch: %v", wrapper)
+	}
+}
+
+func TestMaxUnwrapDepth(t *testing.T) {
+	defer func() { MaxUnwrapDepth = 100 }()
+
+	var err error = io.EOF
+	for i := 0; i < 9; i++ {
+		err = wrappingError{"layer", err}
+	}
+
+	if !Is(err, io.EOF) || Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Is should reach the end of a chain within the limit")
+	}
+
+	MaxUnwrapDepth = 5
+	if Is(err, io.EOF) {
+		t.Errorf("Is should stop at the limit")
+	}
+
+	var target *lookupError
+	cause := &lookupError{Key: "user"}
+	shallow := wrappingError{"a", wrappingError{"b", cause}}
+	deep := wrappingError{"a", wrappingError{"b", wrappingError{"c", wrappingError{"d", wrappingError{"e", cause}}}}}
+	if !As(shallow, &target) || target != cause {
+		t.Errorf("As should find a target within the limit")
+	}
+	target = nil
+	if As(deep, &target) || target != nil {
+		t.Errorf("As should stop at the limit")
+	}
+
+	if New(err).RootMessage() == "EOF" || strings.Count(New(err).Chain(), "layer") != 4 {
+		t.Errorf("Chain and RootMessage should stop at the limit: %s", New(err).Chain())
+	}
+
+	MaxUnwrapDepth = 0
+	if !Is(err, io.EOF) || !As(deep, &target) {
+		t.Errorf("A limit of 0 should not bound the chain")
+	}
+}
+
+func TestMatchMessage(t *testing.T) {
+	err := WrapPrefix(New(wrappingError{"lookup", &lookupError{"peer (code 104)"}}), "load", 0)
+
+	if !MatchMessage(err, regexp.MustCompile(`^missing peer \(code \d+\)$`)) {
+		t.Errorf("Buried message should match")
+	}
+
+	if MatchMessage(err, regexp.MustCompile(`timeout`)) || MatchMessage(nil, regexp.MustCompile(``)) {
+		t.Errorf("MatchMessage matched the wrong error")
+	}
+}
@@ -0,0 +1,76 @@
+package errors
+
+import (
+	"strings"
+)
+
+// CheckInterface verifies that err provides the same methods as *Error, so
+// that code written for this package can use it, and returns a descriptive
+// error if it does not. It is intended for tests of error types that aim to
+// be compatible with this package.
+func CheckInterface(err error) error {
+	if err == nil {
+		return Errorf("errors.CheckInterface: error is nil")
+	}
+
+	var missing []string
+	check := func(ok bool, method string) {
+		if !ok {
+			missing = append(missing, method)
+		}
+	}
+
+	unwrapper, ok := err.(interface{ Unwrap() error })
+	check(ok, "Unwrap() error")
+	callers, ok := err.(interface{ Callers() []uintptr })
+	check(ok, "Callers() []uintptr")
+	stacker, ok := err.(interface{ Stack() []byte })
+	check(ok, "Stack() []byte")
+	errorStacker, ok := err.(interface{ ErrorStack() string })
+	check(ok, "ErrorStack() string")
+	framer, ok := err.(interface{ StackFrames() []StackFrame })
+	check(ok, "StackFrames() []StackFrame")
+	typeNamer, ok := err.(interface{ TypeName() string })
+	check(ok, "TypeName() string")
+
+	if len(missing) > 0 {
+		return Errorf("errors.CheckInterface: %T is missing %s", err, strings.Join(missing, ", "))
+	}
+
+	if unwrapper.Unwrap() == nil {
+		return Errorf("errors.CheckInterface: %T.Unwrap returned nil", err)
+	}
+	if typeNamer.TypeName() == "" {
+		return Errorf("errors.CheckInterface: %T.TypeName returned an empty string", err)
+	}
+	if !strings.Contains(errorStacker.ErrorStack(), err.Error()) {
+		return Errorf("errors.CheckInterface: %T.ErrorStack does not contain the message", err)
+	}
+
+	frames := framer.StackFrames()
+	if pcs := callers.Callers(); pcs != nil && len(pcs) != len(frames) {
+		return Errorf("errors.CheckInterface: %T has %d callers but %d stack frames", err, len(pcs), len(frames))
+	}
+	if len(frames) > 0 && len(stacker.Stack()) == 0 {
+		return Errorf("errors.CheckInterface: %T.Stack is empty but it has stack frames", err)
+	}
+
+	return nil
+}
+
+// HasStack reports whether any *Error in err's chain has a stacktrace.
+func HasStack(err error) bool {
+	return outermost(err, func(e *Error) bool { return len(e.stack) > 0 || len(e.StackFrames()) > 0 }) != nil
+}
+
+// AssertHasStack returns a descriptive error if err is not nil and has no
+// stacktrace from this package, as reported by HasStack. It can be used in
+// tests, or in debug builds at the boundaries of a service, to check that
+// errors are wrapped before they are returned. It walks the whole chain, so
+// it is not intended for hot paths.
+func AssertHasStack(err error) error {
+	if err == nil || HasStack(err) {
+		return nil
+	}
+	return Errorf("errors.AssertHasStack: %T has no stacktrace: %v", err, err)
+}
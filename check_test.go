@@ -0,0 +1,58 @@
+package errors
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+type brokenStackError struct{ err *Error }
+
+func (e brokenStackError) Error() string             { return e.err.Error() }
+func (e brokenStackError) Unwrap() error             { return e.err.Unwrap() }
+func (e brokenStackError) Callers() []uintptr        { return []uintptr{1} }
+func (e brokenStackError) Stack() []byte             { return e.err.Stack() }
+func (e brokenStackError) ErrorStack() string        { return e.err.ErrorStack() }
+func (e brokenStackError) StackFrames() []StackFrame { return e.err.StackFrames() }
+func (e brokenStackError) TypeName() string          { return e.err.TypeName() }
+
+func TestCheckInterface(t *testing.T) {
+	if err := CheckInterface(New(io.EOF)); err != nil {
+		t.Errorf("*Error should satisfy the interface: %v", err)
+	}
+
+	if err := CheckInterface(io.EOF); err == nil || !strings.Contains(err.Error(), "missing Unwrap() error, Callers() []uintptr") {
+		t.Errorf("Missing methods were not reported: %v", err)
+	}
+
+	if err := CheckInterface(brokenStackError{New(io.EOF)}); err == nil || !strings.Contains(err.Error(), "has 1 callers but") {
+		t.Errorf("Misbehaving method was not reported: %v", err)
+	}
+
+	if err := CheckInterface(nil); err == nil {
+		t.Errorf("Nil error was not reported")
+	}
+}
+
+func TestAssertHasStack(t *testing.T) {
+	if err := AssertHasStack(WrapPrefix(io.EOF, "read", 0)); err != nil {
+		t.Errorf("Wrapped error should have a stack: %v", err)
+	}
+
+	if err := AssertHasStack(wrappingError{"read", New(io.EOF)}); err != nil {
+		t.Errorf("Stack further down the chain should be found: %v", err)
+	}
+
+	err := AssertHasStack(wrappingError{"read", io.EOF})
+	if err == nil || !strings.Contains(err.Error(), "errors.wrappingError has no stacktrace: read") {
+		t.Errorf("Missing stack was not reported: %v", err)
+	}
+
+	if !HasStack(err) || HasStack(io.EOF) || HasStack(&Error{Err: io.EOF}) {
+		t.Errorf("HasStack is wrong")
+	}
+
+	if AssertHasStack(nil) != nil {
+		t.Errorf("Nil error should pass")
+	}
+}
@@ -0,0 +1,42 @@
+package errors
+
+// WithCode returns a copy of the error with a machine-readable code attached,
+// such as "user_not_found", which callers can check with HasCode instead of
+// comparing messages. The code is kept when the error is wrapped further.
+func (err *Error) WithCode(code string) *Error {
+	coded := err.clone()
+	coded.code = code
+	return coded
+}
+
+// Code returns the code attached with WithCode to the outermost *Error in
+// err's chain that has one, or "" if there is none.
+func Code(err error) string {
+	if codes := Codes(err); len(codes) > 0 {
+		return codes[0]
+	}
+	return ""
+}
+
+// HasCode reports whether any *Error in err's chain has the given code.
+func HasCode(err error, code string) bool {
+	for _, c := range Codes(err) {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Codes returns every code attached with WithCode in err's chain, outermost
+// first. A code is only listed once when consecutive layers have the same
+// code, as happens when an error is copied by WrapPrefix.
+func Codes(err error) []string {
+	var codes []string
+	for _, layer := range layersOf(err) {
+		if layer.code != "" && (len(codes) == 0 || codes[len(codes)-1] != layer.code) {
+			codes = append(codes, layer.code)
+		}
+	}
+	return codes
+}
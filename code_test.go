@@ -0,0 +1,34 @@
+package errors
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestCodes(t *testing.T) {
+	inner := New(io.EOF).WithCode("eof")
+	middle := New(wrappingError{"read", inner}).WithCode("read_failed")
+	outer := New(wrappingError{"load", WrapPrefix(middle, "config", 0)}).WithCode("config_invalid")
+
+	if codes := Codes(outer); !reflect.DeepEqual(codes, []string{"config_invalid", "read_failed", "eof"}) {
+		t.Errorf("Wrong codes: %v", codes)
+	}
+
+	if Code(outer) != "config_invalid" || Code(middle) != "read_failed" || Code(io.EOF) != "" {
+		t.Errorf("Wrong outermost code: %q", Code(outer))
+	}
+
+	if !HasCode(outer, "eof") || HasCode(outer, "missing") {
+		t.Errorf("Wrong HasCode")
+	}
+
+	repeated := New(wrappingError{"again", inner}).WithCode("eof")
+	if codes := Codes(repeated); !reflect.DeepEqual(codes, []string{"eof"}) {
+		t.Errorf("Adjacent codes should be deduplicated: %v", codes)
+	}
+
+	if Codes(io.EOF) != nil || Codes(nil) != nil {
+		t.Errorf("Errors without codes should have none")
+	}
+}
@@ -0,0 +1,44 @@
+package errors
+
+// Combine joins a and b into a single error with a stacktrace that points to
+// the line of code that called Combine, as with Join(a, b). Unlike Join, the
+// details and levels attached anywhere in the chains of a and b are carried
+// over to the new error. If both have a detail with the same key the one from
+// b is kept, and the most severe level of the two is used, counting an error
+// without a level as LevelError. Combine returns nil if both a and b are nil.
+func Combine(a, b error) *Error {
+	joined := Join(a, b)
+	if joined == nil {
+		return nil
+	}
+
+	err := newError(config(), joined, 1)
+	level := Level(0)
+	for _, operand := range []error{a, b} {
+		if operand == nil {
+			continue
+		}
+		err.details = append(err.details, chainDetails(operand)...)
+		if l := LevelOf(operand); l > level {
+			level = l
+		}
+	}
+	if level != LevelError {
+		// An error without a level is already treated as LevelError.
+		err.level = level
+	}
+
+	return record(err)
+}
+
+// chainDetails returns the details attached to every *Error in err's chain,
+// ordered so that details from outer errors replace those from inner ones.
+func chainDetails(err error) []detail {
+	layers := layersOf(err)
+
+	var details []detail
+	for i := len(layers) - 1; i >= 0; i-- {
+		details = append(details, layers[i].details...)
+	}
+	return details
+}
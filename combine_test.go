@@ -0,0 +1,53 @@
+package errors
+
+import (
+	"io"
+	"testing"
+)
+
+type stringer string
+
+func (s stringer) String() string { return string(s) }
+
+func TestCombine(t *testing.T) {
+	a := New(io.EOF).WithDetail("user", stringer("alice")).WithDetail("attempt", stringer("1")).WithLevel(LevelWarn)
+	b := WrapPrefix(New(io.ErrUnexpectedEOF).WithDetail("attempt", stringer("2")), "retry", 0).WithDetail("host", stringer("db1"))
+
+	err := Combine(a, b)
+	if err.Error() != "EOF\nretry: unexpected EOF" {
+		t.Errorf("Wrong message: %q", err.Error())
+	}
+
+	if !Is(err, io.EOF) || !Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Combined error should match both operands")
+	}
+
+	details := err.Details()
+	if len(details) != 3 || details["user"].String() != "alice" || details["host"].String() != "db1" {
+		t.Errorf("Details were not merged: %v", details)
+	}
+
+	if details["attempt"].String() != "2" {
+		t.Errorf("Later operand should win for duplicate keys: %s", details["attempt"])
+	}
+
+	if err.Level() != LevelError {
+		t.Errorf("An operand without a level should count as error: %s", err.Level())
+	}
+
+	if level := Combine(New(io.EOF).WithLevel(LevelDebug), New(io.ErrUnexpectedEOF)).Level(); level != LevelError {
+		t.Errorf("An operand without a level should count as error: %s", level)
+	}
+
+	if level := Combine(New(io.EOF).WithLevel(LevelDebug), b.WithLevel(LevelWarn)).Level(); level != LevelWarn {
+		t.Errorf("Level was not merged: %s", level)
+	}
+
+	if frame, _ := err.TopFrame(); frame.Name != "TestCombine" {
+		t.Errorf("Stack should start at the call to Combine: %s", frame.Name)
+	}
+
+	if Combine(io.EOF, nil).Error() != "EOF" || Combine(nil, nil) != nil {
+		t.Errorf("Nil operands were not handled")
+	}
+}
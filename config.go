@@ -0,0 +1,169 @@
+package errors
+
+import (
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// Config holds the settings of this package. Each field has the same meaning
+// as the package variable of the same name. The zero Config is not the default
+// configuration: a zero MaxStackDepth captures no stacks and a zero
+// MaxUnwrapDepth removes the limit on unwrapping, for example, so a Config
+// should be built by changing the one returned by CurrentConfig.
+type Config struct {
+	MaxStackDepth            int
+	CaptureFunc              func(skip int, depth int) []uintptr
+	StackSeparator           string
+	HeaderFormat             func(typeName, msg string) string
+	RelativePaths            bool
+	SiteSampleRate           int
+	DeadlineCaptureThreshold time.Duration
+	IncludeBuildInfo         bool
+	Logger                   func(*Error)
+	MaxUnwrapDepth           int
+	NilYieldsNil             bool
+	CaptureStackMinLevel     Level
+	MaxAnnotations           int
+	CollapseRecursion        bool
+	LayerDeltas              bool
+	Now                      func() time.Time
+	FatalPredicate           func(error) bool
+	RetryableSentinels       []error
+}
+
+// configuration is a Config passed to Configure, with the values the package
+// variables had at the time.
+type configuration struct {
+	Config
+	variables Config
+}
+
+var configured atomic.Value // *configuration
+
+// Configure replaces the settings of this package with c. It is safe to call
+// concurrently with creating and formatting errors: each operation reads the
+// settings once, and so sees either the old or the new configuration as a
+// whole.
+//
+// Every field of c is used as is, including zero ones: Configure(Config{Logger:
+// f}) also sets MaxStackDepth to 0, so that errors have no stack, and clears
+// RetryableSentinels. To change a single setting start from CurrentConfig.
+// Only a nil CaptureFunc, which captures the real stack, and a nil Now, which
+// uses time.Now, are replaced by their defaults.
+//
+// A package variable such as MaxStackDepth that is set after Configure is
+// called overrides the corresponding field of c, so that code which sets the
+// variables keeps working. Unlike Configure, setting a variable is not safe
+// while errors are being created.
+func Configure(c Config) {
+	if c.CaptureFunc == nil {
+		c.CaptureFunc = captureCallers
+	}
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+	configured.Store(&configuration{c, variables()})
+}
+
+// CurrentConfig returns the settings currently in use: those passed to the
+// last call to Configure, with any package variables set since then, or, if
+// it has not been called, the values of the package variables.
+func CurrentConfig() Config {
+	return config()
+}
+
+func config() Config {
+	v := variables()
+	c, _ := configured.Load().(*configuration)
+	if c == nil {
+		return v
+	}
+
+	cfg, old := c.Config, c.variables
+	if v.MaxStackDepth != old.MaxStackDepth {
+		cfg.MaxStackDepth = v.MaxStackDepth
+	}
+	if !sameFunc(v.CaptureFunc, old.CaptureFunc) {
+		cfg.CaptureFunc = v.CaptureFunc
+	}
+	if v.StackSeparator != old.StackSeparator {
+		cfg.StackSeparator = v.StackSeparator
+	}
+	if !sameFunc(v.HeaderFormat, old.HeaderFormat) {
+		cfg.HeaderFormat = v.HeaderFormat
+	}
+	if v.RelativePaths != old.RelativePaths {
+		cfg.RelativePaths = v.RelativePaths
+	}
+	if v.SiteSampleRate != old.SiteSampleRate {
+		cfg.SiteSampleRate = v.SiteSampleRate
+	}
+	if v.DeadlineCaptureThreshold != old.DeadlineCaptureThreshold {
+		cfg.DeadlineCaptureThreshold = v.DeadlineCaptureThreshold
+	}
+	if v.IncludeBuildInfo != old.IncludeBuildInfo {
+		cfg.IncludeBuildInfo = v.IncludeBuildInfo
+	}
+	if !sameFunc(v.Logger, old.Logger) {
+		cfg.Logger = v.Logger
+	}
+	if v.MaxUnwrapDepth != old.MaxUnwrapDepth {
+		cfg.MaxUnwrapDepth = v.MaxUnwrapDepth
+	}
+	if v.NilYieldsNil != old.NilYieldsNil {
+		cfg.NilYieldsNil = v.NilYieldsNil
+	}
+	if v.CaptureStackMinLevel != old.CaptureStackMinLevel {
+		cfg.CaptureStackMinLevel = v.CaptureStackMinLevel
+	}
+	if v.MaxAnnotations != old.MaxAnnotations {
+		cfg.MaxAnnotations = v.MaxAnnotations
+	}
+	if v.CollapseRecursion != old.CollapseRecursion {
+		cfg.CollapseRecursion = v.CollapseRecursion
+	}
+	if v.LayerDeltas != old.LayerDeltas {
+		cfg.LayerDeltas = v.LayerDeltas
+	}
+	if !sameFunc(v.Now, old.Now) {
+		cfg.Now = v.Now
+	}
+	if !sameFunc(v.FatalPredicate, old.FatalPredicate) {
+		cfg.FatalPredicate = v.FatalPredicate
+	}
+	if len(v.RetryableSentinels) != len(old.RetryableSentinels) || len(v.RetryableSentinels) > 0 && &v.RetryableSentinels[0] != &old.RetryableSentinels[0] {
+		cfg.RetryableSentinels = v.RetryableSentinels
+	}
+	return cfg
+}
+
+// variables returns the settings held in the package variables.
+func variables() Config {
+	return Config{
+		MaxStackDepth:            MaxStackDepth,
+		CaptureFunc:              CaptureFunc,
+		StackSeparator:           StackSeparator,
+		HeaderFormat:             HeaderFormat,
+		RelativePaths:            RelativePaths,
+		SiteSampleRate:           SiteSampleRate,
+		DeadlineCaptureThreshold: DeadlineCaptureThreshold,
+		IncludeBuildInfo:         IncludeBuildInfo,
+		Logger:                   Logger,
+		MaxUnwrapDepth:           MaxUnwrapDepth,
+		NilYieldsNil:             NilYieldsNil,
+		CaptureStackMinLevel:     CaptureStackMinLevel,
+		MaxAnnotations:           MaxAnnotations,
+		CollapseRecursion:        CollapseRecursion,
+		LayerDeltas:              LayerDeltas,
+		Now:                      Now,
+		FatalPredicate:           FatalPredicate,
+		RetryableSentinels:       RetryableSentinels,
+	}
+}
+
+// sameFunc reports whether a and b, which are functions of the same type,
+// are the same function.
+func sameFunc(a, b interface{}) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestConfigure(t *testing.T) {
+	defer configured.Store((*configuration)(nil))
+
+	if cfg := CurrentConfig(); cfg.MaxStackDepth != MaxStackDepth || cfg.StackSeparator != StackSeparator {
+		t.Errorf("Default config should mirror the package variables")
+	}
+
+	cfg := CurrentConfig()
+	cfg.MaxStackDepth = 1
+	cfg.StackSeparator = "\n--\n"
+	Configure(cfg)
+
+	err := New(io.EOF)
+	if len(err.StackFrames()) != 1 {
+		t.Errorf("Configured depth was not used: %d frames", len(err.StackFrames()))
+	}
+
+	if err.ErrorStack() != "*errors.errorString EOF\n--\n"+string(err.Stack()) {
+		t.Errorf("Configured separator was not used: %q", err.ErrorStack())
+	}
+
+	if CurrentConfig().StackSeparator != "\n--\n" || StackSeparator != "\n" {
+		t.Errorf("Configure should not change the package variables")
+	}
+
+	Configure(Config{MaxStackDepth: 3})
+	if len(New(io.EOF).StackFrames()) != 3 {
+		t.Errorf("A nil CaptureFunc should capture the real stack")
+	}
+}
+
+func TestConfigureConcurrently(t *testing.T) {
+	defer configured.Store((*configuration)(nil))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(depth int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cfg := CurrentConfig()
+				cfg.MaxStackDepth = depth
+				Configure(cfg)
+			}
+		}(i + 1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if err := New(io.EOF); len(err.StackFrames()) > 4 {
+					t.Errorf("Stack is deeper than any configured depth")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConfigureVariables(t *testing.T) {
+	defer configured.Store((*configuration)(nil))
+	defer func() { MaxStackDepth, FatalPredicate, RetryableSentinels = 50, nil, []error{io.ErrUnexpectedEOF} }()
+
+	cfg := CurrentConfig()
+	cfg.MaxStackDepth = 2
+	cfg.RetryableSentinels = []error{io.EOF}
+	cfg.FatalPredicate = func(err error) bool { return err == io.EOF }
+	Configure(cfg)
+
+	if CurrentConfig().Now == nil || !Retryable(io.EOF) || !IsFatal(io.EOF) {
+		t.Errorf("Configured sentinels and predicate were not used")
+	}
+
+	MaxStackDepth = 1
+	FatalPredicate = func(error) bool { return false }
+	if len(New(io.EOF).StackFrames()) != 1 || IsFatal(io.EOF) {
+		t.Errorf("Package variables set after Configure should be used")
+	}
+
+	if !Retryable(io.EOF) || CurrentConfig().StackSeparator != StackSeparator {
+		t.Errorf("Settings whose variables were not set should be kept")
+	}
+
+	RetryableSentinels = append(RetryableSentinels, io.EOF)
+	if !Retryable(io.EOF) || !Retryable(io.ErrUnexpectedEOF) {
+		t.Errorf("Extended sentinels should be used")
+	}
+}
@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"context"
+	"time"
+)
+
+// DeadlineCaptureThreshold is the minimum time that must be left before the
+// deadline of the context passed to WrapCtx for it to capture a stacktrace.
+//
+// Deprecated: Use Configure.
+var DeadlineCaptureThreshold = time.Millisecond
+
+// WrapCtx is like Wrap, but does not capture a stacktrace if ctx is already
+// done or has less than DeadlineCaptureThreshold left before its deadline.
+// This trades debugging information for latency on paths that are about to
+// time out anyway. The skip parameter behaves as for Wrap.
+func WrapCtx(ctx context.Context, e interface{}, skip int) *Error {
+	if e == nil {
+		return nil
+	}
+
+	if err, ok := e.(*Error); ok {
+		return err
+	}
+
+	cfg := config()
+	if ctx.Err() != nil {
+		return record(errorFromValue(cfg, e))
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < cfg.DeadlineCaptureThreshold {
+		return record(errorFromValue(cfg, e))
+	}
+
+	return record(newError(cfg, e, 1+skip))
+}
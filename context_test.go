@@ -0,0 +1,44 @@
+package errors
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWrapCtx(t *testing.T) {
+	err := WrapCtx(context.Background(), io.EOF, 0)
+	if err.Err != io.EOF || len(err.StackFrames()) == 0 {
+		t.Errorf("Stack should be captured without a deadline")
+	}
+
+	if frame, _ := err.TopFrame(); frame.Name != "TestWrapCtx" {
+		t.Errorf("Stack should start at the caller: %s", frame.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	if len(WrapCtx(ctx, io.EOF, 0).StackFrames()) == 0 {
+		t.Errorf("Stack should be captured when there is enough time left")
+	}
+
+	expiring, cancelExpiring := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancelExpiring()
+	<-expiring.Done()
+
+	err = WrapCtx(expiring, "timed out", 0)
+	if err.Error() != "timed out" || len(err.StackFrames()) != 0 {
+		t.Errorf("Stack should not be captured after the deadline")
+	}
+
+	near, cancelNear := context.WithTimeout(context.Background(), DeadlineCaptureThreshold/2)
+	defer cancelNear()
+	if len(WrapCtx(near, io.EOF, 0).StackFrames()) != 0 {
+		t.Errorf("Stack should not be captured close to the deadline")
+	}
+
+	if WrapCtx(ctx, nil, 0) != nil {
+		t.Errorf("Constructor with nil failed")
+	}
+}
@@ -0,0 +1,76 @@
+package errors
+
+import (
+	"bytes"
+	"runtime"
+	"sync/atomic"
+)
+
+var createdByEnabled int32
+
+// EnableCreatedBy makes new errors record the go statement that started the
+// goroutine they were created in, which is returned by CreatedBy. This helps
+// to trace errors from deep asynchronous chains back to where the work was
+// started. It is disabled by default, as finding the creator means formatting
+// the goroutine's stack with runtime.Stack each time an error is created.
+func EnableCreatedBy(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&createdByEnabled, 1)
+	} else {
+		atomic.StoreInt32(&createdByEnabled, 0)
+	}
+}
+
+// CreatedBy returns the go statement that started the goroutine the error was
+// created in. It returns false if EnableCreatedBy had not been called when the
+// error was created, or if the creator was not known, as is the case for the
+// main goroutine. This is a best effort, based on the "created by" line that
+// the runtime writes at the end of a goroutine's stack.
+func (err *Error) CreatedBy() (StackFrame, bool) {
+	if err.createdBy == nil {
+		return StackFrame{}, false
+	}
+	return *err.createdBy, true
+}
+
+// maxCreatedByStack is the largest stack that captureCreatedBy will format
+// looking for the creator of a goroutine.
+const maxCreatedByStack = 64 << 10
+
+// captureCreatedBy returns the creator of the current goroutine, or nil if
+// EnableCreatedBy has not been called or it could not be found.
+func captureCreatedBy() *StackFrame {
+	if atomic.LoadInt32(&createdByEnabled) == 0 {
+		return nil
+	}
+
+	buf := make([]byte, 4<<10)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		if len(buf) >= maxCreatedByStack {
+			// The creator is at the end, which has been cut off.
+			return nil
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	idx := bytes.LastIndex(buf, []byte("\ncreated by "))
+	if idx == -1 {
+		return nil
+	}
+
+	lines := bytes.SplitN(buf[idx+len("\ncreated by "):], []byte("\n"), 3)
+	if len(lines) < 2 {
+		return nil
+	}
+
+	frame, err := parsePanicFrame(string(lines[0]), string(lines[1]), true)
+	if err != nil {
+		return nil
+	}
+	return frame
+}
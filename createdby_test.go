@@ -0,0 +1,54 @@
+package errors
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCreatedBy(t *testing.T) {
+	if _, ok := New(io.EOF).CreatedBy(); ok {
+		t.Errorf("CreatedBy should be disabled by default")
+	}
+
+	EnableCreatedBy(true)
+	defer EnableCreatedBy(false)
+
+	result := make(chan *Error)
+	go func() {
+		result <- New(io.EOF)
+	}()
+	err := <-result
+
+	frame, ok := err.CreatedBy()
+	if !ok {
+		t.Fatalf("CreatedBy should be recorded")
+	}
+
+	if frame.Name != "TestCreatedBy" || !strings.HasSuffix(frame.File, "createdby_test.go") || frame.LineNumber == 0 {
+		t.Errorf("CreatedBy should point at the go statement: %s:%d %s", frame.File, frame.LineNumber, frame.Name)
+	}
+
+	if _, ok := WrapPrefix(err, "read", 0).CreatedBy(); !ok {
+		t.Errorf("WrapPrefix should keep CreatedBy")
+	}
+
+	values := make(chan *Error)
+	go func() {
+		values <- New("boom")
+	}()
+	if frame, ok := (<-values).CreatedBy(); !ok || frame.Name != "TestCreatedBy" {
+		t.Errorf("CreatedBy should be recorded for errors made from values: %s %v", frame.Name, ok)
+	}
+
+	panicked, ok := (<-Go(func() error {
+		c()
+		return nil
+	})).(*Error)
+	if !ok {
+		t.Fatalf("Panic should be delivered as an *Error")
+	}
+	if frame, ok := panicked.CreatedBy(); !ok || frame.Name != "Go" {
+		t.Errorf("CreatedBy should be recorded for panics recovered by Go: %s %v", frame.Name, ok)
+	}
+}
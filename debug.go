@@ -0,0 +1,148 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DebugString returns everything known about the error in a readable form for
+// interactive debugging. It writes, each part only if it is set:
+//
+//   - the message;
+//   - "[key=value ...]" with the details attached anywhere in the chain and
+//     the code and level, sorted by key;
+//   - "ops: a > b" with the operations added with WithOp;
+//   - "at line L col C" with the position set with WithPosition;
+//   - the notes added with AddNote, one per line as "- note";
+//   - the stack of each *Error in the chain, outermost first, leaving out the
+//     frames it shares with the next *Error so that each frame appears once.
+//
+// If LayerDeltas is set, the header of each wrapping stack also gives the
+// time between the creation of the next *Error and of the one that wraps it,
+// e.g. "wrapped at (+12ms):".
+func (err *Error) DebugString() string {
+	var buf bytes.Buffer
+	buf.WriteString(err.Error() + "\n")
+
+	context := map[string]string{}
+	for _, d := range chainDetails(err) {
+		context[d.key] = detailValue(d.value)
+	}
+	if code := Code(err); code != "" {
+		context["code"] = code
+	}
+	if level := chainLevel(err); level != 0 {
+		context["level"] = level.String()
+	}
+	if len(context) > 0 {
+		pairs := make([]string, 0, len(context))
+		for key, value := range context {
+			pairs = append(pairs, key+"="+value)
+		}
+		sort.Strings(pairs)
+		buf.WriteString("[" + strings.Join(pairs, " ") + "]\n")
+	}
+	if ops := err.Ops(); len(ops) > 0 {
+		buf.WriteString("ops: " + strings.Join(ops, " > ") + "\n")
+	}
+	if line, col, ok := err.Position(); ok {
+		fmt.Fprintf(&buf, "at line %d col %d\n", line, col)
+	}
+	if notes := renderNotes(err.Notes()); notes != "" {
+		buf.WriteString(notes[1:] + "\n")
+	}
+
+	layers := layersOf(err)
+
+	deltas := config().LayerDeltas
+	for i, layer := range layers {
+		frames := layer.StackFrames()
+		if i < len(layers)-1 {
+			frames = trimSharedFrames(frames, layers[i+1].StackFrames())
+		}
+		if len(frames) == 0 {
+			continue
+		}
+
+		if i == len(layers)-1 {
+			buf.WriteString("created at:\n")
+		} else if inner := layers[i+1]; deltas && !layer.created.IsZero() && !inner.created.IsZero() {
+			fmt.Fprintf(&buf, "wrapped at (+%s):\n", layer.created.Sub(inner.created))
+		} else {
+			buf.WriteString("wrapped at:\n")
+		}
+		for _, frame := range renderedFrames(frames) {
+			buf.WriteString(frame.String())
+		}
+	}
+
+	return buf.String()
+}
+
+// DebugStack returns the stack in the format of runtime/debug.Stack, so that
+// tools which parse Go tracebacks can read it unchanged: a
+// "goroutine N [running]:" header, then for each frame a line with the
+// function and its arguments and an indented line with the file, the line
+// number and the offset of the program counter. The goroutine that created
+// the error is not recorded, so the header always uses goroutine 1, and the
+// arguments are written as "..." unless they are known from ParsePanic.
+func (err *Error) DebugStack() []byte {
+	buf := bytes.Buffer{}
+	buf.WriteString("goroutine 1 [running]:\n")
+
+	for _, frame := range renderedFrames(err.StackFrames()) {
+		name := frame.Name
+		if frame.Package != "" {
+			name = frame.Package + "." + name
+		}
+		args := frame.Args()
+		if args == "" {
+			args = "..."
+		}
+		fmt.Fprintf(&buf, "%s(%s)\n\t%s:%d", name, args, frame.File, frame.LineNumber)
+
+		if fn := frame.Func(); fn != nil && frame.ProgramCounter > fn.Entry() {
+			fmt.Fprintf(&buf, " +0x%x", frame.ProgramCounter-fn.Entry())
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.Bytes()
+}
+
+// StackDiff compares the stacks of the outermost *Error in the chains of a
+// and b from the bottom up, to show where two code paths diverged. common
+// holds the frames at the bottom of both stacks, and aOnly and bOnly hold the
+// frames above them that only a or only b has. All three are ordered
+// innermost first, like StackFrames. Frames are the same if they have the
+// same file, function and line. If either error has no *Error in its chain,
+// all three are empty.
+func StackDiff(a, b error) (common []StackFrame, aOnly []StackFrame, bOnly []StackFrame) {
+	var errA, errB *Error
+	if !As(a, &errA) || !As(b, &errB) {
+		return nil, nil, nil
+	}
+
+	framesA, framesB := errA.StackFrames(), errB.StackFrames()
+	aOnly = trimSharedFrames(framesA, framesB)
+	bOnly = framesB[:len(framesB)-(len(framesA)-len(aOnly))]
+	common = framesA[len(aOnly):]
+	return common, aOnly, bOnly
+}
+
+// trimSharedFrames returns frames without the trailing frames that it has in
+// common with inner.
+func trimSharedFrames(frames, inner []StackFrame) []StackFrame {
+	n, m := len(frames), len(inner)
+	for n > 0 && m > 0 && sameFrame(frames[n-1], inner[m-1]) {
+		n--
+		m--
+	}
+	return frames[:n]
+}
+
+func sameFrame(a, b StackFrame) bool {
+	return a.File == b.File && a.LineNumber == b.LineNumber && a.Package == b.Package && a.Name == b.Name
+}
@@ -0,0 +1,100 @@
+package errors
+
+import (
+	"io"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestDebugString(t *testing.T) {
+	inner := New(io.EOF).WithDetail("file", stringer("config.yml")).WithCode("eof")
+	outer := WrapPrefix(New(inner), "load", 0).WithDetail("attempt", stringer("2")).WithLevel(LevelWarn)
+
+	lines := strings.Split(outer.DebugString(), "\n")
+	if lines[0] != "load: EOF" || lines[1] != "[attempt=2 code=eof file=config.yml level=warn]" {
+		t.Errorf("Wrong header:\n%s", outer.DebugString())
+	}
+
+	if lines[2] != "wrapped at:" || strings.Count(outer.DebugString(), "created at:\n") != 1 {
+		t.Errorf("Both stacks should be written:\n%s", outer.DebugString())
+	}
+
+	for _, frame := range inner.StackFrames()[1:] {
+		if strings.Count(outer.DebugString(), frame.String()) != 1 {
+			t.Errorf("Shared frame should be written once: %s", frame.String())
+		}
+	}
+
+	plain := New(io.EOF)
+	if plain.DebugString() != "EOF\ncreated at:\n"+string(plain.Stack()) {
+		t.Errorf("Wrong output without context:\n%s", plain.DebugString())
+	}
+}
+
+var tracebackLine = regexp.MustCompile(`^(goroutine \d+ \[running\]:|created by .*|\S+\(.*\)|\t\S+:\d+( \+0x[0-9a-f]+)?)$`)
+
+func TestDebugStack(t *testing.T) {
+	err := New(io.EOF)
+	stack := strings.TrimSuffix(string(err.DebugStack()), "\n")
+	real := strings.TrimSuffix(string(debug.Stack()), "\n")
+
+	for _, sample := range []string{real, stack} {
+		for _, line := range strings.Split(sample, "\n") {
+			if !tracebackLine.MatchString(line) {
+				t.Errorf("Line does not match the traceback format: %q", line)
+			}
+		}
+	}
+
+	lines := strings.Split(stack, "\n")
+	if len(lines) != 1+2*len(err.StackFrames()) || lines[1] != "github.com/go-errors/errors.TestDebugStack(...)" {
+		t.Errorf("Wrong stack:\n%s", stack)
+	}
+
+	parsed, e := ParsePanic("panic: EOF\n\n" + stack)
+	if e != nil {
+		t.Fatal(e)
+	}
+	for i, frame := range parsed.StackFrames() {
+		expected := err.StackFrames()[i]
+		if frame.File != expected.File || frame.LineNumber != expected.LineNumber || frame.Name != expected.Name || frame.Package != expected.Package {
+			t.Errorf("Frame %d was not parsed back: %v != %v", i, frame, expected)
+		}
+	}
+}
+
+func TestStackDiff(t *testing.T) {
+	root := []StackFrame{
+		{File: "/src/app/main.go", LineNumber: 20, Name: "run", Package: "main"},
+		{File: "/src/app/main.go", LineNumber: 8, Name: "main", Package: "main"},
+	}
+	a := &Error{Err: io.EOF, frames: append([]StackFrame{
+		{File: "/src/app/db.go", LineNumber: 40, Name: "query", Package: "main"},
+		{File: "/src/app/load.go", LineNumber: 12, Name: "load", Package: "main"},
+	}, root...)}
+	b := &Error{Err: io.EOF, frames: append([]StackFrame{
+		{File: "/src/app/cache.go", LineNumber: 7, Name: "get", Package: "main"},
+		{File: "/src/app/load.go", LineNumber: 15, Name: "load", Package: "main"},
+	}, root...)}
+
+	common, aOnly, bOnly := StackDiff(a, wrappingError{"wrapped", b})
+	if len(common) != 2 || common[0].Name != "run" || common[1].Name != "main" {
+		t.Errorf("Wrong common frames: %v", common)
+	}
+	if len(aOnly) != 2 || aOnly[0].Name != "query" || aOnly[1].LineNumber != 12 {
+		t.Errorf("Wrong frames only in a: %v", aOnly)
+	}
+	if len(bOnly) != 2 || bOnly[0].Name != "get" || bOnly[1].LineNumber != 15 {
+		t.Errorf("Wrong frames only in b: %v", bOnly)
+	}
+
+	if common, aOnly, bOnly := StackDiff(a, a); len(common) != 4 || len(aOnly)+len(bOnly) != 0 {
+		t.Errorf("Identical stacks should be entirely common: %v %v %v", common, aOnly, bOnly)
+	}
+
+	if common, aOnly, bOnly := StackDiff(a, io.EOF); len(common)+len(aOnly)+len(bOnly) != 0 {
+		t.Errorf("Errors without a stack should have an empty diff")
+	}
+}
@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"fmt"
+	"time"
+)
+
+type detail struct {
+	key   string
+	value fmt.Stringer
+}
+
+// WithDetail returns a copy of the error with a detail attached under the
+// given key, for example the input that a parser failed on. Details are only
+// converted to strings when the error is rendered by ErrorStack, so large
+// values cost nothing unless they are needed. A later detail replaces an
+// earlier one with the same key. A nil v is rendered as "<nil>". Details may
+// be dropped once there are MaxAnnotations of them.
+func (err *Error) WithDetail(key string, v fmt.Stringer) *Error {
+	detailed := err.clone()
+
+	if max := config().MaxAnnotations; max > 0 {
+		for i, d := range err.details {
+			if d.key == key {
+				detailed.details = append([]detail(nil), err.details...)
+				detailed.details[i].value = v
+				return detailed
+			}
+		}
+		if len(err.details) >= max {
+			detailed.notes = withDroppedNote(err.notes)
+			return detailed
+		}
+	}
+
+	detailed.details = append(append([]detail(nil), err.details...), detail{key, v})
+	return detailed
+}
+
+// Details returns the details attached to this error with WithDetail, keyed
+// by name. Their String methods are not called.
+func (err *Error) Details() map[string]fmt.Stringer {
+	details := make(map[string]fmt.Stringer, len(err.details))
+	for _, d := range err.details {
+		details[d.key] = d.value
+	}
+	return details
+}
+
+// WithDuration returns a copy of the error with the duration of the operation
+// that failed attached as the "duration" detail, so that it is included by
+// ErrorStack and MarshalJSON. This helps spot errors from operations that
+// were also slow.
+func (err *Error) WithDuration(d time.Duration) *Error {
+	return err.WithDetail("duration", d)
+}
+
+// Duration returns the duration attached to this error with WithDuration, or
+// 0 if there is none. It also reads the duration of an error decoded by
+// UnmarshalJSON.
+func (err *Error) Duration() time.Duration {
+	switch d := err.Details()["duration"].(type) {
+	case time.Duration:
+		return d
+	case fmt.Stringer:
+		parsed, _ := time.ParseDuration(d.String())
+		return parsed
+	}
+	return 0
+}
+
+// renderDetails returns the details as lines of "key: value", in the order
+// they were attached, for ErrorStack.
+func (err *Error) renderDetails() string {
+	var str string
+	for i, d := range err.details {
+		if err.detailReplaced(i) {
+			continue
+		}
+		str += "\n" + d.key + ": " + detailValue(d.value)
+	}
+	return str
+}
+
+// detailValue returns the string form of a detail's value, or "<nil>" if it
+// was attached as nil.
+func detailValue(v fmt.Stringer) string {
+	if v == nil {
+		return "<nil>"
+	}
+	return v.String()
+}
+
+// detailReplaced reports whether the detail at index i was replaced by a
+// later detail with the same key.
+func (err *Error) detailReplaced(i int) bool {
+	for _, d := range err.details[i+1:] {
+		if d.key == err.details[i].key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type countingStringer struct {
+	value string
+	calls int
+}
+
+func (s *countingStringer) String() string {
+	s.calls++
+	return s.value
+}
+
+func TestWithDetail(t *testing.T) {
+	input := &countingStringer{value: "{\"broken\": }"}
+	base := New(io.EOF)
+	err := base.WithDetail("input", input)
+
+	if err.Error() != "EOF" || len(base.Details()) != 0 {
+		t.Errorf("WithDetail changed the message or the original error")
+	}
+
+	if err.Details()["input"] != input || input.calls != 0 {
+		t.Errorf("Detail was not attached lazily")
+	}
+
+	stack := err.ErrorStack()
+	if input.calls != 1 {
+		t.Errorf("Detail should be rendered exactly once, got %d", input.calls)
+	}
+
+	if !strings.HasPrefix(stack, "*errors.errorString EOF\ninput: {\"broken\": }\n") {
+		t.Errorf("Detail was not rendered: %s", stack)
+	}
+
+	replaced := err.WithDetail("input", &countingStringer{value: "new"})
+	if stack := replaced.ErrorStack(); strings.Contains(stack, "broken") || !strings.Contains(stack, "\ninput: new\n") {
+		t.Errorf("Later detail should replace an earlier one: %s", stack)
+	}
+}
+
+func TestWithDetailNil(t *testing.T) {
+	err := New(io.EOF).WithDetail("input", nil)
+
+	if stack := err.ErrorStack(); !strings.Contains(stack, "\ninput: <nil>\n") {
+		t.Errorf("Nil detail was not rendered: %s", stack)
+	}
+
+	fields, _ := err.ProblemDetails()["fields"].(map[string]interface{})
+	if !strings.Contains(err.DebugString(), "[input=<nil>]") || fields["input"] != "<nil>" || LogrusFields(err)["input"] != "<nil>" {
+		t.Errorf("Nil detail was not rendered everywhere: %s", err.DebugString())
+	}
+
+	if out, e := json.Marshal(err); e != nil || !strings.Contains(string(out), `"input":"\u003cnil\u003e"`) {
+		t.Errorf("Nil detail was not encoded: %s %v", out, e)
+	}
+}
+
+func TestWithDuration(t *testing.T) {
+	err := New(io.EOF).WithDuration(1500 * time.Millisecond)
+
+	if err.Duration() != 1500*time.Millisecond || New(io.EOF).Duration() != 0 {
+		t.Errorf("Wrong duration: %v", err.Duration())
+	}
+
+	if !strings.Contains(err.ErrorStack(), "\nduration: 1.5s\n") {
+		t.Errorf("Duration is missing from ErrorStack: %s", err.ErrorStack())
+	}
+
+	data := mustMarshal(t, err)
+	if !strings.Contains(string(data), `"duration":"1.5s"`) {
+		t.Errorf("Duration is missing from MarshalJSON: %s", data)
+	}
+
+	var decoded Error
+	if e := json.Unmarshal(data, &decoded); e != nil {
+		t.Fatal(e)
+	}
+	if decoded.Duration() != 1500*time.Millisecond {
+		t.Errorf("Duration was not decoded: %v", decoded.Duration())
+	}
+}
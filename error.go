@@ -48,13 +48,56 @@ package errors
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"path/filepath"
 	"reflect"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // The maximum number of stackframes on any error.
+//
+// Deprecated: Use Configure, which can safely be called while errors are
+// being created.
 var MaxStackDepth = 50
 
+// NilYieldsNil makes New(nil) return nil, as Wrap(nil) does, instead of an
+// error with the message "<nil>". It is off by default for compatibility. It
+// can also be set with Configure.
+var NilYieldsNil = false
+
+// CaptureFunc is used to capture the stack of every new error. It should
+// return the program counters of at most depth frames, starting skip frames
+// above its caller, in the same way as runtime.Callers. It defaults to
+// capturing the real stack, and is intended to be replaced in tests that
+// need a deterministic stack.
+//
+// Deprecated: Use Configure.
+var CaptureFunc = captureCallers
+
+// StackSeparator is written between the header line and the stack by
+// ErrorStack.
+//
+// Deprecated: Use Configure.
+var StackSeparator = "\n"
+
+// HeaderFormat, if set, formats the header line written by ErrorStack from the
+// error's TypeName and message. By default the header is the type name and
+// the message separated by a space.
+//
+// Deprecated: Use Configure.
+var HeaderFormat func(typeName, msg string) string
+
+func captureCallers(skip int, depth int) []uintptr {
+	stack := make([]uintptr, depth)
+	length := runtime.Callers(2+skip, stack[:])
+	return stack[:length]
+}
+
 // Error is an error with an attached stacktrace. It can be used
 // wherever the builtin error interface is expected.
 type Error struct {
@@ -62,28 +105,110 @@ type Error struct {
 	stack  []uintptr
 	frames []StackFrame
 	prefix string
+
+	// repeats is the number of calls folded into each frame of stack when
+	// CollapseRecursion is set.
+	repeats []int
+
+	// site is the program counter of the line of code that created the
+	// error, if only that was recorded or the stack was dropped because of
+	// CaptureStackMinLevel. WithLevel expands it into the full stack.
+	site uintptr
+
+	// value is the original value passed to New or Wrap when it was not
+	// already an error.
+	value interface{}
+
+	level Level
+
+	details []detail
+
+	// retryable is set by MarkRetryable.
+	retryable bool
+
+	// fatal is set by MarkFatal.
+	fatal bool
+
+	// panicked is set for errors made from a recovered panic.
+	panicked bool
+
+	// fieldPath is the dotted path of the input field the error is about,
+	// set by WithFieldPath.
+	fieldPath string
+
+	// protos are the messages attached with WithDetailProto.
+	protos []ProtoMessage
+
+	// ops are the names of the operations that failed, outermost first,
+	// added by WithOp.
+	ops []string
+
+	// position is the place in the input being parsed that the error is
+	// about, set by WithPosition.
+	position *inputPosition
+
+	// frozen is set by Freeze. It is not copied by clone, as copies are new
+	// errors that can be changed.
+	frozen bool
+
+	code       string
+	traceID    string
+	httpStatus int
+	payload    []byte
+	notes      []string
+
+	// created is when the error was made, as returned by Time.
+	created time.Time
+
+	// seq is the error's sequence number, if EnableSequence was called.
+	seq uint64
+
+	// createdBy is the go statement that started the goroutine the error
+	// was made in, if EnableCreatedBy was called.
+	createdBy *StackFrame
+
+	// logged is set atomically once the error has been logged.
+	logged uint32
+
+	// framesOnce guards the lazy resolution of frames so that errors which
+	// are shared between goroutines can be rendered concurrently.
+	framesOnce sync.Once
 }
 
 // New makes an Error from the given value. If that value is already an
 // error then it will be used directly, if not, it will be passed to
 // fmt.Errorf("%v"). The stacktrace will point to the line of code that
-// called New.
+// called New. New(nil) returns an error with the message "<nil>", unless
+// NilYieldsNil is set.
 func New(e interface{}) *Error {
-	var err error
-
-	switch e := e.(type) {
-	case error:
-		err = e
-	default:
-		err = fmt.Errorf("%v", e)
+	cfg := config()
+	if e == nil && cfg.NilYieldsNil {
+		return nil
 	}
+	return record(newError(cfg, e, 1))
+}
 
-	stack := make([]uintptr, MaxStackDepth)
-	length := runtime.Callers(2, stack[:])
-	return &Error{
-		Err:   err,
-		stack: stack[:length],
+// NewFromFrames makes an Error from the given value using frames that have
+// already been resolved, for example by a profiler, instead of capturing the
+// current stack. The value is converted to an error in the same way as New.
+func NewFromFrames(e interface{}, frames *runtime.Frames) *Error {
+	cfg := config()
+	err := errorFromValue(cfg, e)
+
+	depth := cfg.MaxStackDepth
+	stack := make([]StackFrame, 0, depth)
+	for frames != nil && len(stack) < depth {
+		frame, more := frames.Next()
+		if frame.PC != 0 || frame.Function != "" {
+			stack = append(stack, stackFrameFromRuntime(frame))
+		}
+		if !more {
+			break
+		}
 	}
+
+	err.frames = stack
+	return record(err)
 }
 
 // Wrap makes an Error from the given value. If that value is already an *Error
@@ -98,23 +223,113 @@ func Wrap(e interface{}, skip int) *Error {
 		return nil
 	}
 
-	var err error
-
-	switch e := e.(type) {
+	switch err := e.(type) {
 	case *Error:
-		return e
+		return err
 	case error:
-		err = e
-	default:
-		err = fmt.Errorf("%v", e)
+		return record(newWrapped(config(), err, 1+skip))
 	}
 
-	stack := make([]uintptr, MaxStackDepth)
-	length := runtime.Callers(2+skip, stack[:])
-	return &Error{
-		Err:   err,
-		stack: stack[:length],
+	return record(newError(config(), e, 1+skip))
+}
+
+// WrapPC is like Wrap, but the stacktrace starts at the frame of the given
+// program counter, as returned by runtime.Caller, rather than a number of
+// frames up. This lets a helper attribute the error to its caller however
+// deeply the helper itself is nested:
+//
+//	pc, _, _, _ := runtime.Caller(1)
+//	return errors.WrapPC(err, pc)
+//
+// If pc is not on the current goroutine's stack, the stacktrace only has the
+// frame of pc.
+func WrapPC(e interface{}, pc uintptr) *Error {
+	if e == nil {
+		return nil
+	}
+
+	if err, ok := e.(*Error); ok {
+		return err
+	}
+
+	err := newError(config(), e, 1)
+	if err.stack == nil {
+		return record(err)
+	}
+
+	for i, caller := range err.stack {
+		// The stack holds return addresses, which runtime.Caller
+		// reports one byte earlier.
+		if caller == pc || caller == pc+1 {
+			err.stack = err.stack[i:]
+			if err.repeats != nil {
+				err.repeats = err.repeats[i:]
+			}
+			return record(err)
+		}
 	}
+
+	err.stack, err.repeats = []uintptr{pc + 1}, nil
+	return record(err)
+}
+
+// WrapN behaves exactly like Wrap, and also reports whether a new stacktrace
+// was captured. The bool is false when e is nil or already an *Error (which is
+// returned without modification), and true when e is any other error or
+// value, which is wrapped with a stacktrace starting skip frames up.
+func WrapN(e interface{}, skip int) (*Error, bool) {
+	if e == nil {
+		return nil, false
+	}
+
+	if err, ok := e.(*Error); ok {
+		return err, false
+	}
+
+	return record(newError(config(), e, 1+skip)), true
+}
+
+// WrapIf behaves like Wrap if cond is true, and returns nil otherwise. This
+// keeps sequences of checks short, e.g. return errors.WrapIf(n > max, err, 0).
+// The skip parameter behaves as for Wrap.
+func WrapIf(cond bool, e interface{}, skip int) *Error {
+	if !cond {
+		return nil
+	}
+	return Wrap(e, 1+skip)
+}
+
+// FirstError wraps the first of errs that is not nil, as Wrap does, with a
+// stacktrace that points to the line of code that called FirstError. Unlike
+// Join, the other errors are discarded, which suits cleanup sequences where
+// only the first failure matters. It returns nil if every error is nil.
+func FirstError(errs ...error) *Error {
+	for _, err := range errs {
+		if err != nil {
+			return Wrap(err, 1)
+		}
+	}
+	return nil
+}
+
+// WrapOnce makes an Error from the given value with a new stacktrace, even if
+// that value is already an *Error. However if the value is an *Error whose
+// stacktrace was captured at the same call site, it is returned without
+// modification. This avoids piling up identical layers when the same error is
+// wrapped on every pass through a loop. The skip parameter behaves as for
+// Wrap.
+func WrapOnce(e interface{}, skip int) *Error {
+	if e == nil {
+		return nil
+	}
+
+	err := newError(config(), e, 1+skip)
+
+	if existing, ok := e.(*Error); ok && len(err.stack) > 0 && len(existing.stack) > 0 && existing.stack[0] == err.stack[0] {
+		return existing
+	}
+
+	return record(err)
 }
 
 // WrapPrefix makes an Error from the given value. If that value is already an
@@ -131,25 +346,226 @@ func WrapPrefix(e interface{}, prefix string, skip int) *Error {
 		return nil
 	}
 
-	err := Wrap(e, 1+skip)
+	err, ok := e.(*Error)
+	if !ok {
+		err = newError(config(), e, 1+skip)
+	}
 
 	if err.prefix != "" {
 		prefix = fmt.Sprintf("%s: %s", prefix, err.prefix)
 	}
 
-	return &Error{
-		Err:    err.Err,
-		stack:  err.stack,
-		prefix: prefix,
+	prefixed := err.clone()
+	prefixed.prefix = prefix
+	return record(prefixed)
+
+}
+
+// WrapPrefixForce is like WrapPrefix, but always wraps the value in a new
+// *Error with a new stacktrace, even if it is already an *Error. Where
+// WrapPrefix keeps the stacktrace of an existing *Error and only adds the
+// prefix to its message, WrapPrefixForce keeps the existing *Error intact as
+// the wrapped error, so both stacktraces are available. This is useful where
+// an error crosses a goroutine boundary, as the original stacktrace does not
+// show how the receiving goroutine got there. The skip parameter behaves as
+// for WrapPrefix.
+func WrapPrefixForce(e interface{}, prefix string, skip int) *Error {
+	if e == nil {
+		return nil
+	}
+
+	err := newError(config(), e, 1+skip)
+	err.prefix = prefix
+	return record(err)
+}
+
+// Wrapf wraps err in a new *Error with a stacktrace that points to the line
+// of code that called Wrapf, and a prefix formatted from format and a, as
+// fmt.Sprintf does. The new error's message is the prefix followed by err's
+// message, and err is always kept as the wrapped error, so Unwrap, Is and As
+// reach it as they would through fmt.Errorf with %w. Wrapf returns nil if err
+// is nil.
+func Wrapf(err error, format string, a ...interface{}) *Error {
+	if err == nil {
+		return nil
+	}
+
+	return WrapPrefixForce(err, fmt.Sprintf(format, a...), 1)
+}
+
+// Annotate adds prefix to the error that errp points to, if it is not nil,
+// as WrapPrefix does. It is intended to be deferred with a pointer to a named
+// error result, so that every error a function returns gets the same context
+// without repeating it at each return:
+//
+//	func load(path string) (err error) {
+//		defer errors.Annotate(&err, "loading "+path)
+//		...
+//	}
+//
+// An error that is not yet an *Error gets a stacktrace that points into the
+// function that deferred Annotate. It does nothing if *errp is nil.
+func Annotate(errp *error, prefix string) {
+	if *errp != nil {
+		*errp = WrapPrefix(*errp, prefix, 1)
+	}
+}
+
+// Annotatef is like Annotate, with a prefix formatted from format and a as
+// fmt.Sprintf does. The prefix is only formatted if *errp is not nil.
+func Annotatef(errp *error, format string, a ...interface{}) {
+	if *errp != nil {
+		*errp = WrapPrefix(*errp, fmt.Sprintf(format, a...), 1)
 	}
+}
 
+// Restack returns a copy of err with a new stacktrace that points to the line
+// of code that called Restack, keeping its message, details, code and other
+// annotations. Unlike WrapPrefixForce it does not add a layer: the copy
+// replaces err rather than wrapping it. This is useful when an error is
+// handed from a worker to another goroutine, so the stack shows the handoff.
+// If err is not an *Error it is wrapped as Wrap does. Restack returns nil if
+// err is nil.
+func Restack(err error) *Error {
+	e, ok := err.(*Error)
+	if !ok {
+		return Wrap(err, 1)
+	}
+
+	restacked := e.clone()
+	restacked.stack, restacked.repeats, restacked.site = capture(config(), 1)
+	restacked.frames = nil
+	return record(restacked)
+}
+
+// WrapCaller behaves like WrapPrefix, using the name of the calling function
+// as the prefix, e.g. "doThing: original message". This keeps the prefix
+// accurate when the function is renamed. The skip parameter selects both the
+// function whose name is used and where the stacktrace starts: 0 is the
+// function that called WrapCaller, 1 its caller, etc.
+func WrapCaller(e interface{}, skip int) *Error {
+	if e == nil {
+		return nil
+	}
+
+	var name string
+	if pc, _, _, ok := runtime.Caller(1 + skip); ok {
+		if fn := runtime.FuncForPC(pc - 1); fn != nil {
+			_, name = packageAndName(fn)
+		}
+	}
+
+	return WrapPrefix(e, name, 1+skip)
 }
 
 // Errorf creates a new error with the given message. You can use it
 // as a drop-in replacement for fmt.Errorf() to provide descriptive
 // errors in return values.
 func Errorf(format string, a ...interface{}) *Error {
-	return Wrap(fmt.Errorf(format, a...), 1)
+	return record(newWrapped(config(), fmt.Errorf(format, a...), 1))
+}
+
+// NewWrap creates a new error that adds msg as context to cause, with a
+// stacktrace that points to the line of code that called NewWrap. Its
+// message is msg followed by ": " and the message of cause, and it unwraps to
+// cause, so Is and As can reach it. A new stacktrace is captured even if
+// cause is already an *Error. If cause is nil the error only has msg as its
+// message.
+func NewWrap(msg string, cause error) *Error {
+	if cause == nil {
+		return record(newWrapped(config(), fmt.Errorf("%s", msg), 1))
+	}
+
+	err := newWrapped(config(), cause, 1)
+	err.prefix = msg
+	return record(err)
+}
+
+// Ok panics if err is not nil. The panic value is an *Error with a
+// stacktrace that points to the line of code that called Ok, unless err is
+// already an *Error in which case it is used directly. This is useful in
+// tests and setup code where any error should stop execution immediately,
+// e.g. errors.Ok(db.Ping()).
+func Ok(err error) {
+	if err != nil {
+		panic(Wrap(err, 1))
+	}
+}
+
+// newError makes a new Error from the given value, which is converted to an
+// error as described for New, with a stacktrace that starts skip frames above
+// the caller of newError. Unlike the exported constructors it does not record
+// the error.
+func newError(cfg Config, e interface{}, skip int) *Error {
+	if err, ok := e.(error); ok {
+		return newWrapped(cfg, err, 1+skip)
+	}
+
+	err := errorFromValue(cfg, e)
+	err.stack, err.repeats, err.site = capture(cfg, 1+skip)
+	err.createdBy = captureCreatedBy()
+	return err
+}
+
+// newWrapped is newError for a value that is already known to be an error,
+// such as the result of fmt.Errorf, which saves converting it. Like newError
+// it does not record the error.
+func newWrapped(cfg Config, err error, skip int) *Error {
+	wrapped := &Error{Err: err, created: cfg.Now(), seq: nextSeq()}
+	wrapped.stack, wrapped.repeats, wrapped.site = capture(cfg, 1+skip)
+	wrapped.createdBy = captureCreatedBy()
+	return wrapped
+}
+
+// errorFromValue makes a new Error without a stacktrace from the given value.
+// If that value is already an error it will be used directly, if not, it will
+// be passed to fmt.Errorf("%v") and kept as the original value.
+func errorFromValue(cfg Config, e interface{}) *Error {
+	switch e := e.(type) {
+	case error:
+		return &Error{Err: e, created: cfg.Now(), seq: nextSeq()}
+	default:
+		return &Error{Err: fmt.Errorf("%v", e), value: e, created: cfg.Now(), seq: nextSeq()}
+	}
+}
+
+// clone returns a shallow copy of err, which the With* methods modify so
+// that annotating an error never changes an error that may be shared.
+func (err *Error) clone() *Error {
+	c := &Error{
+		Err:        err.Err,
+		stack:      err.stack,
+		repeats:    err.repeats,
+		site:       err.site,
+		prefix:     err.prefix,
+		value:      err.value,
+		level:      err.level,
+		details:    err.details,
+		retryable:  err.retryable,
+		fatal:      err.fatal,
+		panicked:   err.panicked,
+		fieldPath:  err.fieldPath,
+		ops:        err.ops,
+		protos:     err.protos,
+		position:   err.position,
+		code:       err.code,
+		traceID:    err.traceID,
+		httpStatus: err.httpStatus,
+		payload:    err.payload,
+		notes:      err.notes,
+		created:    err.created,
+		seq:        err.seq,
+		createdBy:  err.createdBy,
+		logged:     atomic.LoadUint32(&err.logged),
+	}
+
+	// Frames that were not resolved from the stack were supplied when the
+	// error was created, so they are never written to and are safe to share.
+	if len(err.stack) == 0 {
+		c.frames = err.frames
+	}
+
+	return c
 }
 
 // Error returns the underlying error's message.
@@ -163,52 +579,333 @@ func (err *Error) Error() string {
 	return msg
 }
 
+// Format implements fmt.Formatter. The %+v verb writes the message followed
+// by the notes added with AddNote, one per line as "- note", and %#v writes
+// the Go syntax of the struct as it would without this method. Every other
+// verb formats the message as it would a string, so %v and %s are the same
+// as Error.
+func (err *Error) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		io.WriteString(s, err.Error()+renderNotes(err.Notes()))
+		return
+	}
+	if verb == 'v' && s.Flag('#') {
+		// plain has no methods, so it is formatted as a struct, but it has
+		// to be renamed back to Error.
+		type plain Error
+		syntax := fmt.Sprintf("%#v", (*plain)(err))
+		io.WriteString(s, strings.Replace(syntax, "errors.plain{", "errors.Error{", 1))
+		return
+	}
+
+	directive := "%"
+	for _, flag := range "+-# 0" {
+		if s.Flag(int(flag)) {
+			directive += string(flag)
+		}
+	}
+	if width, ok := s.Width(); ok {
+		directive += strconv.Itoa(width)
+	}
+	if precision, ok := s.Precision(); ok {
+		directive += "." + strconv.Itoa(precision)
+	}
+	fmt.Fprintf(s, directive+string(verb), err.Error())
+}
+
 // Stack returns the callstack formatted the same way that go does
 // in runtime/debug.Stack()
 func (err *Error) Stack() []byte {
 	buf := bytes.Buffer{}
 
-	for _, frame := range err.StackFrames() {
+	for _, frame := range renderedFrames(err.StackFrames()) {
 		buf.WriteString(frame.String())
 	}
 
 	return buf.Bytes()
 }
 
+// CompactStack returns the callstack with one frame per line in the compact
+// form given by StackFrame.Short.
+func (err *Error) CompactStack() string {
+	buf := bytes.Buffer{}
+
+	for _, frame := range renderedFrames(err.StackFrames()) {
+		buf.WriteString(frame.Short())
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
+
 // Callers satisfies the bugsnag ErrorWithCallerS() interface
 // so that the stack can be read out.
 func (err *Error) Callers() []uintptr {
 	return err.stack
 }
 
+// StackIsSynthetic reports whether the error's stack frames were
+// reconstructed without program counters, as for errors made by ParsePanic or
+// decoded by UnmarshalJSON, so that they cannot be resolved again, e.g. by
+// Symbolicate. The frames passed to NewFromFrames keep their program
+// counters, so they are only synthetic if those are missing. It returns false
+// for an error without any stack frames.
+func (err *Error) StackIsSynthetic() bool {
+	if err.stack != nil {
+		return false
+	}
+	for _, frame := range err.StackFrames() {
+		if frame.ProgramCounter == 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // ErrorStack returns a string that contains both the
-// error message and the callstack.
+// error message and the callstack. Any notes added with AddNote and details
+// attached with WithDetail are written on their own lines between the two.
 func (err *Error) ErrorStack() string {
-	return err.TypeName() + " " + err.Error() + "\n" + string(err.Stack())
+	cfg := config()
+
+	var header string
+	if cfg.HeaderFormat != nil {
+		header = cfg.HeaderFormat(err.TypeName(), err.Error())
+	} else {
+		header = err.TypeName() + " " + err.Error()
+	}
+	return header + renderNotes(err.Notes()) + err.renderDetails() + cfg.StackSeparator + string(err.Stack())
 }
 
 // StackFrames returns an array of frames containing information about the
-// stack.
+// stack. It is safe to call from multiple goroutines.
 func (err *Error) StackFrames() []StackFrame {
-	if err.frames == nil {
-		err.frames = make([]StackFrame, len(err.stack))
+	err.framesOnce.Do(func() {
+		// Without a stack the frames, if any, were supplied when the error
+		// was created, and are left alone so that they can be read without
+		// synchronization.
+		if err.frames != nil || len(err.stack) == 0 {
+			return
+		}
+
+		err.frames = resolveFrames(err.stack)
+	})
+
+	return err.frames
+}
 
-		for i, pc := range err.stack {
-			err.frames[i] = NewStackFrame(pc)
+// Repeats returns, for each frame returned by StackFrames, the number of
+// further consecutive calls of its function, as made by recursion, that were
+// folded into it because CollapseRecursion was set when the stack was
+// captured. It returns nil if the stack was captured without collapsing
+// recursion.
+func (err *Error) Repeats() []int {
+	if err.stack == nil || err.repeats == nil {
+		return nil
+	}
+	return append([]int(nil), err.repeats...)
+}
+
+// resolveFrames returns the stack frames for the program counters of stack.
+func resolveFrames(stack []uintptr) []StackFrame {
+	frames := make([]StackFrame, len(stack))
+	for i, pc := range stack {
+		// The frame that called runtime.sigpanic was interrupted by a
+		// signal, so its pc is the faulting instruction rather than a
+		// return address.
+		interrupted := i > 0 && frames[i-1].Package == "runtime" && frames[i-1].Name == "sigpanic"
+		frames[i] = newStackFrame(pc, !interrupted)
+	}
+	return frames
+}
+
+// Symbolicate returns the stack frames of the error, resolving each program
+// counter through m when it is present there and through the runtime
+// otherwise. This supports offline symbolication, where the stack of a
+// stripped binary is resolved with a mapping produced separately. Errors
+// without program counters, such as those from ParsePanic or UnmarshalJSON,
+// return their frames unchanged.
+func (err *Error) Symbolicate(m map[uintptr]StackFrame) []StackFrame {
+	frames := append([]StackFrame(nil), err.StackFrames()...)
+	for i, pc := range err.stack {
+		if frame, ok := m[pc]; ok && i < len(frames) {
+			frames[i] = frame
 		}
 	}
+	return frames
+}
 
-	return err.frames
+// FramesBetween returns the window of stack frames that starts at the
+// first frame in topPkg and ends at the last frame in bottomPkg. This is
+// useful for producing traces that only cover a request handler by
+// dropping the server frames that called it. An empty package leaves that
+// end of the stack unbounded. If either marker cannot be found the full
+// stack is returned.
+func (err *Error) FramesBetween(topPkg, bottomPkg string) []StackFrame {
+	frames := err.StackFrames()
+
+	top := 0
+	if topPkg != "" {
+		top = -1
+		for i, frame := range frames {
+			if frame.Package == topPkg {
+				top = i
+				break
+			}
+		}
+	}
+
+	bottom := len(frames) - 1
+	if bottomPkg != "" {
+		bottom = -1
+		for i := len(frames) - 1; i >= 0 && i >= top; i-- {
+			if frames[i].Package == bottomPkg {
+				bottom = i
+				break
+			}
+		}
+	}
+
+	if top == -1 || bottom == -1 || bottom < top {
+		return frames
+	}
+
+	return frames[top : bottom+1]
+}
+
+// TopFrame returns the frame at which the stack was captured, usually the
+// line of code that created the error. The boolean is false if the error has
+// no stack.
+func (err *Error) TopFrame() (StackFrame, bool) {
+	frames := err.StackFrames()
+	if len(frames) == 0 {
+		return StackFrame{}, false
+	}
+	return frames[0], true
+}
+
+// FirstAppFrame returns the topmost frame that belongs to the application,
+// as reported by StackFrame.InApp, skipping frames in the standard library
+// and in dependencies such as logging helpers. If no frame belongs to the
+// application the top frame is returned. The boolean is false if the error
+// has no stack.
+func (err *Error) FirstAppFrame() (StackFrame, bool) {
+	frames := err.StackFrames()
+	for _, frame := range frames {
+		if frame.InApp() {
+			return frame, true
+		}
+	}
+	return err.TopFrame()
+}
+
+// AppPath returns the path that the application's own code took to the
+// error as the file and line of each frame that belongs to the application,
+// as reported by StackFrame.InApp, from where the error was created outwards,
+// e.g. "db.go:40 → handler.go:12 → main.go:8". If no frame belongs to the
+// application every frame is used.
+func (err *Error) AppPath() string {
+	frames := renderedFrames(err.StackFrames())
+
+	var path []string
+	for _, frame := range frames {
+		if frame.InApp() {
+			path = append(path, fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.LineNumber))
+		}
+	}
+
+	if len(path) == 0 {
+		for _, frame := range frames {
+			path = append(path, fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.LineNumber))
+		}
+	}
+
+	return strings.Join(path, " → ")
+}
+
+// OriginPackage returns the package path of FirstAppFrame, e.g.
+// "github.com/foo/bar/db", or "" if the error has no stack. Unlike a file and
+// line, the package makes a label with few distinct values, which suits
+// per-package error metrics.
+func (err *Error) OriginPackage() string {
+	frame, _ := err.FirstAppFrame()
+	return frame.Package
+}
+
+// Summary returns the error's message together with where it came from, as
+// the package-qualified function name and line number of FirstAppFrame, e.g.
+// "github.com/foo/bar.Handle:42". This is enough for a single-line log entry
+// when the full stack is not needed. The origin is empty if the error has no
+// stack.
+func (err *Error) Summary() (msg, origin string) {
+	if frame, ok := err.FirstAppFrame(); ok {
+		origin = fmt.Sprintf("%s.%s:%d", frame.Package, frame.Name, frame.LineNumber)
+	}
+	return err.Error(), origin
+}
+
+// SameOrigin reports whether a and b were created at the same place, that is
+// whether the top frames of their stacks are in the same file and function.
+// Line numbers are ignored. It returns false if either error has no stack.
+func SameOrigin(a, b error) bool {
+	var errA, errB *Error
+	if !As(a, &errA) || !As(b, &errB) {
+		return false
+	}
+
+	topA, ok := errA.TopFrame()
+	if !ok {
+		return false
+	}
+	topB, ok := errB.TopFrame()
+	if !ok {
+		return false
+	}
+
+	return topA.File == topB.File && topA.Package == topB.Package && topA.Name == topB.Name
 }
 
-// TypeName returns the type this error. e.g. *errors.stringError.
+// TypeName returns the type this error. e.g. *errors.stringError. If the
+// error was made from a value that was not an error, the type of that value
+// is returned instead.
 func (err *Error) TypeName() string {
 	if _, ok := err.Err.(uncaughtPanic); ok {
 		return "panic"
 	}
+	if decoded, ok := err.Err.(decodedError); ok {
+		return decoded.typeName
+	}
+	if err.value != nil {
+		return reflect.TypeOf(err.value).String()
+	}
 	return reflect.TypeOf(err.Err).String()
 }
 
+// IsPanic reports whether the error comes from a panic: either one that was
+// recovered by WrapPanic, Go or GoWrapped, or one that was read from a Go
+// traceback by ParsePanic, which TypeName reports as "panic". It also reports
+// true for the latter after it has been decoded by UnmarshalJSON.
+func (err *Error) IsPanic() bool {
+	if err.panicked {
+		return true
+	}
+
+	switch e := err.Err.(type) {
+	case uncaughtPanic:
+		return true
+	case decodedError:
+		return e.typeName == "panic"
+	}
+	return false
+}
+
+// OriginalValue returns the value that was passed to New or Wrap when it was
+// not already an error, for example the value recovered from a panic. It
+// returns nil if the error was made from an error.
+func (err *Error) OriginalValue() interface{} {
+	return err.value
+}
+
 // Return the wrapped error (implements api for As function).
 func (err *Error) Unwrap() error {
 	return err.Err
@@ -4,27 +4,58 @@
 package errors
 
 import (
-	baseErrors "errors"
+	"reflect"
 )
 
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
 // As finds the first error in err's tree that matches target, and if one is found, sets
 // target to that error value and returns true. Otherwise, it returns false.
+// Errors more than MaxUnwrapDepth levels deep are not examined.
 //
 // For more information see stdlib errors.As.
 func As(err error, target interface{}) bool {
-	return baseErrors.As(err, target)
+	if err == nil {
+		return false
+	}
+	if target == nil {
+		panic("errors: target cannot be nil")
+	}
+	val := reflect.ValueOf(target)
+	typ := val.Type()
+	if typ.Kind() != reflect.Ptr || val.IsNil() {
+		panic("errors: target must be a non-nil pointer")
+	}
+	targetType := typ.Elem()
+	if targetType.Kind() != reflect.Interface && !targetType.Implements(errorType) {
+		panic("errors: *target must be interface or implement error")
+	}
+
+	_, found := asWithStack(err, val, nil, unwrapLimit())
+	return found
 }
 
 // Is detects whether the error is equal to a given error. Errors
 // are considered equal by this function if they are matched by errors.Is
-// or if their contained errors are matched through errors.Is.
+// or if their contained errors are matched through errors.Is. Errors more
+// than MaxUnwrapDepth levels deep are not examined.
 func Is(e error, original error) bool {
-	if baseErrors.Is(e, original) {
-		return true
+	if e == nil || original == nil {
+		return e == original
 	}
 
-	if e, ok := e.(*Error); ok {
-		return Is(e.Err, original)
+	isComparable := reflect.TypeOf(original).Comparable()
+	found := false
+	walk(e, func(err error) bool {
+		if isComparable && err == original {
+			found = true
+		} else if x, ok := err.(interface{ Is(error) bool }); ok && x.Is(original) {
+			found = true
+		}
+		return !found
+	})
+	if found {
+		return true
 	}
 
 	if original, ok := original.(*Error); ok {
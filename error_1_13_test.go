@@ -3,6 +3,7 @@
 package errors
 
 import (
+	"fmt"
 	"io"
 	"testing"
 )
@@ -53,6 +54,24 @@ func TestIs113(t *testing.T) {
 	}
 }
 
+func TestIsCustomDeep(t *testing.T) {
+	custErr := errorWithCustomIs{Key: "TestForFun", Err: io.EOF}
+
+	err := WrapPrefix(WrapPrefix(custErr, "inner", 0), "outer", 0)
+	if !Is(err, errorWithCustomIs{Key: "TestForFun"}) {
+		t.Errorf("Custom Is was not called through two prefixes")
+	}
+
+	deep := New(wrappingError{"handler", WrapPrefix(New(wrappingError{"lookup", custErr}), "load", 0)})
+	if !Is(deep, errorWithCustomIs{Key: "TestForFun"}) {
+		t.Errorf("Custom Is was not called through several layers")
+	}
+
+	if Is(deep, errorWithCustomIs{Key: "notOk"}) {
+		t.Errorf("Custom Is matched the wrong key")
+	}
+}
+
 type errorWithCustomIs struct {
 	Key string
 	Err error
@@ -66,3 +85,16 @@ func (ewci errorWithCustomIs) Is(target error) bool {
 	matched, ok := target.(errorWithCustomIs)
 	return ok && matched.Key == ewci.Key
 }
+
+func TestUnwrapped(t *testing.T) {
+	wrapped := fmt.Errorf("read config: %w", io.EOF)
+	err := WrapPrefix(New(wrapped), "load", 0)
+
+	if Unwrapped(WrapPrefixForce(err, "start", 0)) != wrapped {
+		t.Errorf("Unwrapped should return the fmt error")
+	}
+
+	if Unwrapped(io.EOF) != io.EOF || Unwrapped(nil) != nil {
+		t.Errorf("Unwrapped with a plain error or nil failed")
+	}
+}
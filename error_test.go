@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -30,6 +31,14 @@ func BenchmarkStackFormat(b *testing.B) {
 	}
 }
 
+func BenchmarkErrorf(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = Errorf("failed after %d attempts", i)
+	}
+}
+
 func TestAs(t *testing.T) {
 	var errStrIn errorString = "TestForFun"
 
@@ -104,6 +113,31 @@ func TestSkipWorks(t *testing.T) {
 	a()
 }
 
+func TestCaptureFunc(t *testing.T) {
+	defer func() { CaptureFunc = captureCallers }()
+
+	fake := []uintptr{1, 2, 3}
+	var depth int
+	CaptureFunc = func(skip int, d int) []uintptr {
+		depth = d
+		return fake
+	}
+
+	err := New("foo")
+	if !reflect.DeepEqual(err.Callers(), fake) || depth != MaxStackDepth {
+		t.Errorf("Stack was not captured with CaptureFunc")
+	}
+
+	CaptureFunc = func(skip int, d int) []uintptr {
+		return captureCallers(skip+1, d)
+	}
+
+	if err := compareStacks(Wrap("hi", 0).stack, callers()); err != nil {
+		t.Errorf("Skip was not passed to CaptureFunc correctly")
+		t.Errorf(err.Error())
+	}
+}
+
 func TestNew(t *testing.T) {
 
 	err := New("foo")
@@ -130,6 +164,53 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestOriginalValue(t *testing.T) {
+	type payload struct{ ID int }
+
+	err := New(payload{ID: 42})
+	if value, ok := err.OriginalValue().(payload); !ok || value.ID != 42 {
+		t.Errorf("Original value was not preserved")
+	}
+
+	if err.Error() != "{42}" {
+		t.Errorf("Wrong message: %s", err.Error())
+	}
+
+	if err.TypeName() != "errors.payload" {
+		t.Errorf("Wrong type name: %s", err.TypeName())
+	}
+
+	if WrapPrefix(Wrap(7, 0), "prefix", 0).OriginalValue() != 7 {
+		t.Errorf("Original value was not kept by WrapPrefix")
+	}
+
+	if New(io.EOF).OriginalValue() != nil {
+		t.Errorf("Original value should be nil for errors")
+	}
+}
+
+func TestErrorStackFormat(t *testing.T) {
+	defer func() {
+		StackSeparator = "\n"
+		HeaderFormat = nil
+	}()
+
+	err := New(io.EOF)
+
+	StackSeparator = "\n--\n"
+	if err.ErrorStack() != "*errors.errorString EOF\n--\n"+string(err.Stack()) {
+		t.Errorf("Custom separator was not used")
+	}
+
+	StackSeparator = "\t"
+	HeaderFormat = func(typeName, msg string) string {
+		return fmt.Sprintf(`{"type":%q,"msg":%q}`, typeName, msg)
+	}
+	if err.ErrorStack() != `{"type":"*errors.errorString","msg":"EOF"}`+"\t"+string(err.Stack()) {
+		t.Errorf("Custom header was not used: %s", err.ErrorStack())
+	}
+}
+
 // This test should work for any go version
 func TestIs(t *testing.T) {
 	if Is(nil, io.EOF) {
@@ -176,6 +257,126 @@ func TestWrapError(t *testing.T) {
 	}
 }
 
+func TestWrapIf(t *testing.T) {
+	err := WrapIf(true, io.EOF, 0)
+	if err == nil || err.Err != io.EOF {
+		t.Fatalf("Wrapping when the condition holds failed")
+	}
+
+	if frame, _ := err.TopFrame(); frame.Name != "TestWrapIf" {
+		t.Errorf("Stack should start at the call to WrapIf: %s", frame.Name)
+	}
+
+	if WrapIf(false, io.EOF, 0) != nil {
+		t.Errorf("Should not wrap when the condition is false")
+	}
+
+	if WrapIf(true, nil, 0) != nil || WrapIf(false, nil, 0) != nil {
+		t.Errorf("Wrapping nil should return nil")
+	}
+}
+
+func TestFirstError(t *testing.T) {
+	err := FirstError(nil, nil, io.EOF, io.ErrUnexpectedEOF)
+	if err == nil || err.Err != io.EOF {
+		t.Fatalf("Wrong first error: %v", err)
+	}
+
+	if frame, _ := err.TopFrame(); frame.Name != "TestFirstError" {
+		t.Errorf("Stack should start at the call to FirstError: %s", frame.Name)
+	}
+
+	if FirstError(nil, nil) != nil || FirstError() != nil {
+		t.Errorf("Should return nil when every error is nil")
+	}
+}
+
+func TestWrapOnce(t *testing.T) {
+	var err error = io.EOF
+	for i := 0; i < 5; i++ {
+		err = WrapOnce(err, 0)
+	}
+
+	looped := err.(*Error)
+	if looped.Err != io.EOF {
+		t.Errorf("Wrapping in a loop produced more than one layer")
+	}
+
+	outer := WrapOnce(looped, 0)
+	if outer == looped || outer.Err != looped {
+		t.Errorf("Wrapping at a different call site should add a layer")
+	}
+
+	if WrapOnce(nil, 0) != nil {
+		t.Errorf("Constructor with nil failed")
+	}
+}
+
+func TestNilValues(t *testing.T) {
+	defer func() { NilYieldsNil = false }()
+
+	if err := New(nil); err == nil || err.Error() != "<nil>" {
+		t.Errorf("New(nil) should make an error by default: %v", err)
+	}
+
+	if Wrap(nil, 0) != nil {
+		t.Errorf("Wrap(nil) should return nil")
+	}
+
+	NilYieldsNil = true
+	if New(nil) != nil || Wrap(nil, 0) != nil {
+		t.Errorf("New(nil) should return nil with NilYieldsNil")
+	}
+
+	if New(io.EOF) == nil {
+		t.Errorf("NilYieldsNil should not affect other values")
+	}
+}
+
+func TestWrapN(t *testing.T) {
+	err, wrapped := WrapN(io.EOF, 0)
+	if !wrapped || err.Err != io.EOF {
+		t.Errorf("Wrapping an error should capture a stack")
+	}
+
+	if frame, _ := err.TopFrame(); frame.Name != "TestWrapN" {
+		t.Errorf("Stack should start at the call to WrapN: %s", frame.Name)
+	}
+
+	if _, wrapped := WrapN("hi", 0); !wrapped {
+		t.Errorf("Wrapping a string should capture a stack")
+	}
+
+	if same, wrapped := WrapN(err, 0); wrapped || same != err {
+		t.Errorf("Wrapping an Error should return it unchanged")
+	}
+
+	if none, wrapped := WrapN(nil, 0); wrapped || none != nil {
+		t.Errorf("Wrapping nil should return nil")
+	}
+}
+
+func TestOk(t *testing.T) {
+	Ok(nil)
+
+	defer func() {
+		err, ok := recover().(*Error)
+		if !ok {
+			t.Fatalf("Panic value should be an *Error")
+		}
+
+		if err.Err != io.EOF {
+			t.Errorf("Wrong error: %v", err.Err)
+		}
+
+		if frame, _ := err.TopFrame(); frame.Name != "TestOk" {
+			t.Errorf("Stack should start at the call to Ok: %s", frame.Name)
+		}
+	}()
+
+	Ok(io.EOF)
+}
+
 func TestWrapPrefixError(t *testing.T) {
 
 	e := func() error {
@@ -210,6 +411,398 @@ func TestWrapPrefixError(t *testing.T) {
 	}
 }
 
+func TestWrapPrefixForce(t *testing.T) {
+	original := New(io.EOF)
+	err := WrapPrefixForce(original, "worker", 0)
+
+	if err.Error() != "worker: EOF" || err.Err != original {
+		t.Errorf("Wrong wrapped error: %s", err.Error())
+	}
+
+	if err.stack[0] == original.stack[0] {
+		t.Errorf("A new stack should be captured at WrapPrefixForce")
+	}
+
+	if frame, _ := err.TopFrame(); frame.Name != "TestWrapPrefixForce" {
+		t.Errorf("Stack should start at the call to WrapPrefixForce: %s", frame.Name)
+	}
+
+	if WrapPrefixForce(fmt.Errorf("yo"), "prefix", 0).Error() != "prefix: yo" || WrapPrefixForce(nil, "prefix", 0) != nil {
+		t.Errorf("Constructor with an error or nil failed")
+	}
+}
+
+func TestWrapf(t *testing.T) {
+	original := New(io.EOF)
+	err := Wrapf(original, "reading %s at %d", "config", 42)
+
+	if err.Error() != "reading config at 42: EOF" {
+		t.Errorf("Wrong message: %s", err.Error())
+	}
+
+	if err.Unwrap() != original || !Is(err, io.EOF) || !Is(err, original) {
+		t.Errorf("Wrapf should keep the original error reachable")
+	}
+
+	if frame, _ := err.TopFrame(); frame.Name != "TestWrapf" {
+		t.Errorf("Stack should start at the call to Wrapf: %s", frame.Name)
+	}
+
+	if Wrapf(io.EOF, "read").Err != io.EOF || Wrapf(nil, "read") != nil {
+		t.Errorf("Constructor with an error or nil failed")
+	}
+}
+
+func annotated(fail bool) (err error) {
+	defer Annotate(&err, "loading config")
+	if fail {
+		err = io.EOF
+	}
+	return err
+}
+
+func annotatedf(cause error) (err error) {
+	defer Annotatef(&err, "attempt %d", 2)
+	return cause
+}
+
+func TestAnnotate(t *testing.T) {
+	err := annotated(true)
+	if err.Error() != "loading config: EOF" || !Is(err, io.EOF) {
+		t.Errorf("Wrong annotated error: %v", err)
+	}
+
+	if frame, _ := err.(*Error).TopFrame(); frame.Name != "annotated" {
+		t.Errorf("Stack should start in the annotated function: %s", frame.Name)
+	}
+
+	if annotated(false) != nil {
+		t.Errorf("Annotate should not change a nil error")
+	}
+
+	original := New(io.EOF)
+	err = annotatedf(original)
+	if err.Error() != "attempt 2: EOF" || err.(*Error).stack[0] != original.stack[0] {
+		t.Errorf("Annotatef should prefix and keep the stack: %v", err)
+	}
+
+	if annotatedf(nil) != nil {
+		t.Errorf("Annotatef should not change a nil error")
+	}
+}
+
+func wrapPCHelper(err error) *Error {
+	return wrapPCInner(err)
+}
+
+func wrapPCInner(err error) *Error {
+	pc, _, _, _ := runtime.Caller(2)
+	return WrapPC(err, pc)
+}
+
+func TestWrapPC(t *testing.T) {
+	_, _, line, _ := runtime.Caller(0)
+	err := wrapPCHelper(io.EOF)
+
+	if frame, _ := err.TopFrame(); frame.Name != "TestWrapPC" || frame.LineNumber != line+1 {
+		t.Errorf("Stack should start at the given pc: %s:%d", frame.Name, frame.LineNumber)
+	}
+
+	if len(err.StackFrames()) < 2 || err.StackFrames()[1].Package != "testing" {
+		t.Errorf("Stack should continue below the given pc: %v", err.StackFrames())
+	}
+
+	result := make(chan uintptr)
+	go func() {
+		pc, _, _, _ := runtime.Caller(0)
+		result <- pc
+	}()
+	if frames := WrapPC(io.EOF, <-result).StackFrames(); len(frames) != 1 || !strings.HasPrefix(frames[0].Name, "TestWrapPC.func") {
+		t.Errorf("A pc from another stack should be the only frame: %v", frames)
+	}
+
+	if WrapPC(err, 0) != err || WrapPC(nil, 0) != nil {
+		t.Errorf("WrapPC with an *Error or nil failed")
+	}
+}
+
+func TestRestack(t *testing.T) {
+	original := <-Go(func() error {
+		return New(io.EOF).WithCode("eof")
+	})
+
+	err := Restack(original)
+	if err.Error() != original.Error() || Code(err) != "eof" || err.Err != io.EOF {
+		t.Errorf("Restack should keep the error: %s", err.Error())
+	}
+
+	if frame, _ := err.TopFrame(); frame.Name != "TestRestack" {
+		t.Errorf("Stack should start at the call to Restack: %s", frame.Name)
+	}
+
+	if frame, _ := original.(*Error).TopFrame(); frame.Name == "TestRestack" {
+		t.Errorf("Restack modified the original error")
+	}
+
+	if Restack(nil) != nil || Restack(io.EOF).Err != io.EOF {
+		t.Errorf("Restack of nil or a plain error failed")
+	}
+}
+
+//go:noinline
+func wrapCallerHelper(skip int) *Error {
+	return WrapCaller(io.EOF, skip)
+}
+
+func TestWrapCaller(t *testing.T) {
+	if err := wrapCallerHelper(0); err.Error() != "wrapCallerHelper: EOF" {
+		t.Errorf("Wrong prefix: %s", err.Error())
+	}
+
+	err := wrapCallerHelper(1)
+	if err.Error() != "TestWrapCaller: EOF" {
+		t.Errorf("Wrong prefix with skip: %s", err.Error())
+	}
+
+	if frame, _ := err.TopFrame(); frame.Name != "TestWrapCaller" {
+		t.Errorf("Skip should also apply to the stack: %s", frame.Name)
+	}
+
+	if WrapCaller(nil, 0) != nil {
+		t.Errorf("Wrapping nil should return nil")
+	}
+}
+
+func TestNewWrap(t *testing.T) {
+	cause := New(io.EOF)
+	err := NewWrap("reading config", cause)
+
+	if err.Error() != "reading config: EOF" {
+		t.Errorf("Wrong message: %s", err.Error())
+	}
+
+	if !Is(err, io.EOF) || Unwrap(err) != cause {
+		t.Errorf("NewWrap should unwrap to its cause")
+	}
+
+	if frame, _ := err.TopFrame(); frame.Name != "TestNewWrap" || err.stack[0] == cause.stack[0] {
+		t.Errorf("Stack should start at the call to NewWrap: %v", frame)
+	}
+
+	if err := NewWrap("no cause", nil); err.Error() != "no cause" || Unwrap(err.Err) != nil {
+		t.Errorf("Nil cause should only use the message: %s", err.Error())
+	}
+}
+
+func TestSymbolicate(t *testing.T) {
+	err := New(io.EOF)
+	mapped := StackFrame{File: "handler.go", LineNumber: 7, Name: "handle", Package: "example.com/app"}
+
+	frames := err.Symbolicate(map[uintptr]StackFrame{err.stack[0]: mapped})
+	if len(frames) != len(err.StackFrames()) || frames[0] != mapped {
+		t.Fatalf("Mapped frame was not used: %v", frames[0])
+	}
+
+	if frames[1] != err.StackFrames()[1] {
+		t.Errorf("Unmapped frame should be resolved by the runtime: %v", frames[1])
+	}
+
+	if err.StackFrames()[0] == mapped {
+		t.Errorf("Symbolicate modified the error's frames")
+	}
+}
+
+func TestStackIsSynthetic(t *testing.T) {
+	if New(io.EOF).StackIsSynthetic() {
+		t.Errorf("Captured stack should not be synthetic")
+	}
+
+	parsed, e := ParsePanic("panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/src/main.go:5 +0x1d\n")
+	if e != nil {
+		t.Fatal(e)
+	}
+	decoded := &Error{Err: io.EOF, frames: []StackFrame{{File: "/src/main.go", LineNumber: 5, Name: "main", Package: "main"}}}
+	if !parsed.StackIsSynthetic() || !decoded.StackIsSynthetic() {
+		t.Errorf("Frames without program counters should be synthetic")
+	}
+
+	if NewFromFrames(io.EOF, runtime.CallersFrames(callers())).StackIsSynthetic() {
+		t.Errorf("Frames from runtime.CallersFrames should not be synthetic")
+	}
+
+	if (&Error{Err: io.EOF}).StackIsSynthetic() {
+		t.Errorf("Error without frames should not be synthetic")
+	}
+}
+
+func TestFramesBetween(t *testing.T) {
+	err := &Error{Err: fmt.Errorf("boom"), frames: []StackFrame{
+		{Package: "example.com/app/db", Name: "query"},
+		{Package: "example.com/app/handlers", Name: "load"},
+		{Package: "example.com/app/handlers", Name: "Index"},
+		{Package: "net/http", Name: "HandlerFunc.ServeHTTP"},
+		{Package: "net/http", Name: "(*conn).serve"},
+	}}
+
+	frames := err.FramesBetween("example.com/app/db", "example.com/app/handlers")
+	if len(frames) != 3 || frames[0].Name != "query" || frames[2].Name != "Index" {
+		t.Errorf("Wrong frames: %v", frames)
+	}
+
+	frames = err.FramesBetween("example.com/app/handlers", "")
+	if len(frames) != 4 || frames[0].Name != "load" {
+		t.Errorf("Wrong frames for unbounded bottom: %v", frames)
+	}
+
+	if len(err.FramesBetween("example.com/missing", "net/http")) != 5 {
+		t.Errorf("Missing marker should return the full stack")
+	}
+}
+
+func TestConcurrentErrorStack(t *testing.T) {
+	err := Errorf("shared")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = err.ErrorStack()
+		}()
+	}
+	wg.Wait()
+
+	if len(err.StackFrames()) != len(err.stack) {
+		t.Errorf("Wrong number of frames after concurrent access")
+	}
+}
+
+func TestNewFromFrames(t *testing.T) {
+	err := NewFromFrames("foo", runtime.CallersFrames(callers()))
+
+	if err.Error() != "foo" {
+		t.Errorf("Wrong message")
+	}
+
+	frames := err.StackFrames()
+	expected := callersToFrames(callers())
+	if len(frames) != len(expected) {
+		t.Fatalf("Wrong number of frames: %d != %d", len(frames), len(expected))
+	}
+
+	if frames[0].Name != "TestNewFromFrames" || frames[0].Package != "github.com/go-errors/errors" {
+		t.Errorf("Wrong top frame: %s %s", frames[0].Package, frames[0].Name)
+	}
+
+	if !strings.HasSuffix(frames[0].File, "error_test.go") || frames[1].File != expected[1].File || frames[1].LineNumber != expected[1].Line {
+		t.Errorf("Frames were not copied")
+	}
+
+	if len(NewFromFrames("foo", nil).StackFrames()) != 0 {
+		t.Errorf("Nil frames should produce an empty stack")
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	a, b := newFromHelper("a"), newFromHelper("b")
+	if !SameOrigin(a, b) {
+		t.Errorf("Errors from the same function should share an origin")
+	}
+
+	x := New("x")
+	y := New("y")
+	if x.StackFrames()[0].LineNumber == y.StackFrames()[0].LineNumber || !SameOrigin(x, y) {
+		t.Errorf("Errors from the same function on different lines should share an origin")
+	}
+
+	if SameOrigin(a, New("c")) {
+		t.Errorf("Errors from different functions should not share an origin")
+	}
+
+	if SameOrigin(a, fmt.Errorf("plain")) || SameOrigin(fmt.Errorf("plain"), fmt.Errorf("plain")) {
+		t.Errorf("Errors without a stack should not share an origin")
+	}
+
+	if SameOrigin(a, &Error{Err: fmt.Errorf("empty")}) {
+		t.Errorf("Errors with an empty stack should not share an origin")
+	}
+
+	if frame, ok := New("top").TopFrame(); !ok || frame.Name != "TestSameOrigin" {
+		t.Errorf("Wrong top frame: %v", frame)
+	}
+}
+
+func TestAppPath(t *testing.T) {
+	err := &Error{Err: io.EOF, frames: []StackFrame{
+		{File: "/go/src/database/sql/sql.go", LineNumber: 1200, Name: "(*DB).Query", Package: "database/sql"},
+		{File: "/src/app/db.go", LineNumber: 40, Name: "query", Package: "main"},
+		{File: "/go/src/net/http/server.go", LineNumber: 2084, Name: "HandlerFunc.ServeHTTP", Package: "net/http"},
+		{File: "/src/app/main.go", LineNumber: 8, Name: "main", Package: "main"},
+	}}
+
+	if err.AppPath() != "db.go:40 → main.go:8" {
+		t.Errorf("Wrong app path: %s", err.AppPath())
+	}
+
+	err.frames = err.frames[:1]
+	if err.AppPath() != "sql.go:1200" {
+		t.Errorf("Should fall back to every frame: %s", err.AppPath())
+	}
+}
+
+func TestOriginPackage(t *testing.T) {
+	if pkg := New(io.EOF).OriginPackage(); pkg != "github.com/go-errors/errors" {
+		t.Errorf("Wrong origin package: %s", pkg)
+	}
+
+	if pkg := (&Error{Err: io.EOF}).OriginPackage(); pkg != "" {
+		t.Errorf("Error without a stack should have no origin package: %s", pkg)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	_, _, line, _ := runtime.Caller(0)
+	err := New("boom")
+
+	msg, origin := err.Summary()
+	if msg != "boom" {
+		t.Errorf("Wrong message: %s", msg)
+	}
+
+	if want := fmt.Sprintf("github.com/go-errors/errors.TestSummary:%d", line+1); origin != want {
+		t.Errorf("Wrong origin: %s", origin)
+	}
+
+	if _, origin := (&Error{Err: fmt.Errorf("boom")}).Summary(); origin != "" {
+		t.Errorf("Error without a stack should have no origin: %s", origin)
+	}
+}
+
+func TestFirstAppFrame(t *testing.T) {
+	err := &Error{Err: fmt.Errorf("boom"), frames: []StackFrame{
+		{Package: "log", Name: "Println"},
+		{Package: "github.com/go-errors/errors", Name: "handle"},
+		{Package: "net/http", Name: "HandlerFunc.ServeHTTP"},
+	}}
+
+	if frame, ok := err.FirstAppFrame(); !ok || frame.Name != "handle" {
+		t.Errorf("Wrong app frame: %v", frame)
+	}
+
+	err = &Error{Err: fmt.Errorf("boom"), frames: []StackFrame{
+		{Package: "log", Name: "Println"},
+		{Package: "net/http", Name: "HandlerFunc.ServeHTTP"},
+	}}
+
+	if frame, ok := err.FirstAppFrame(); !ok || frame.Name != "Println" {
+		t.Errorf("Should fall back to the top frame: %v", frame)
+	}
+
+	if _, ok := (&Error{Err: fmt.Errorf("boom")}).FirstAppFrame(); ok {
+		t.Errorf("Error without a stack should have no app frame")
+	}
+}
+
 func ExampleErrorf(x int) (int, error) {
 	if x%2 == 1 {
 		return 0, Errorf("can only halve even numbers, got %d", x)
@@ -340,8 +933,28 @@ func callersToFrames(callers []uintptr) []runtime.Frame {
 	}
 }
 
+//go:noinline
+func newFromHelper(msg string) error {
+	return New(msg)
+}
+
 type errorString string
 
 func (e errorString) Error() string {
 	return string(e)
 }
+
+// wrappingError wraps another error in the same way as fmt.Errorf("%s: %w")
+// without needing go1.13.
+type wrappingError struct {
+	msg string
+	err error
+}
+
+func (e wrappingError) Error() string {
+	return e.msg + ": " + e.err.Error()
+}
+
+func (e wrappingError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,45 @@
+package errors
+
+// FatalPredicate, if it is not nil, is called by IsFatal for each error in an
+// error's chain, so that the policy of which errors should stop the process
+// can be kept in one place. It can be set from an init function, or with
+// Configure.
+var FatalPredicate func(error) bool
+
+// IsFatal reports whether err should be treated as unrecoverable. That is the
+// case if any error in err's chain was marked with MarkFatal or satisfies
+// FatalPredicate.
+func IsFatal(err error) bool {
+	predicate := config().FatalPredicate
+
+	fatal := false
+	walk(err, func(err error) bool {
+		if x, ok := err.(*Error); ok && x.fatal {
+			fatal = true
+		} else if predicate != nil && predicate(err) {
+			fatal = true
+		}
+		return !fatal
+	})
+	return fatal
+}
+
+// MarkFatal returns err marked so that IsFatal reports true for it and for
+// any error that wraps it. If err is already an *Error a copy is marked, so
+// shared errors are not modified; otherwise it is wrapped as Wrap does, with
+// a stacktrace that points to the line of code that called MarkFatal.
+// MarkFatal returns nil if err is nil.
+func MarkFatal(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var marked *Error
+	if e, ok := err.(*Error); ok {
+		marked = e.clone()
+	} else {
+		marked = Wrap(err, 1)
+	}
+	marked.fatal = true
+	return marked
+}
@@ -0,0 +1,41 @@
+package errors
+
+import (
+	"io"
+	"os"
+	"testing"
+)
+
+func TestIsFatal(t *testing.T) {
+	if IsFatal(nil) || IsFatal(io.EOF) || IsFatal(New(io.EOF)) {
+		t.Errorf("Errors are not fatal by default")
+	}
+
+	marked := MarkFatal(io.EOF)
+	if !IsFatal(marked) || !IsFatal(WrapPrefix(marked, "read", 0)) || !IsFatal(New(wrappingError{"read", marked})) {
+		t.Errorf("Marked errors should be fatal when wrapped")
+	}
+
+	if frame, _ := marked.TopFrame(); frame.Name != "TestIsFatal" {
+		t.Errorf("Stack should start at the call to MarkFatal: %s", frame.Name)
+	}
+
+	shared := New(io.EOF)
+	if MarkFatal(shared) == shared || IsFatal(shared) {
+		t.Errorf("Marking an *Error should not modify it")
+	}
+
+	if MarkFatal(nil) != nil {
+		t.Errorf("Marking nil should return nil")
+	}
+
+	defer func() { FatalPredicate = nil }()
+	FatalPredicate = func(err error) bool { return err == os.ErrPermission }
+	if !IsFatal(New(wrappingError{"open", os.ErrPermission})) || IsFatal(New(io.EOF)) {
+		t.Errorf("FatalPredicate should be applied to the chain")
+	}
+
+	if !IsFatal(marked) {
+		t.Errorf("Marked errors should stay fatal with a predicate")
+	}
+}
@@ -0,0 +1,28 @@
+package errors
+
+// WithFieldPath returns a copy of the error with the dotted path of the input
+// field that failed validation attached, e.g. "address.zip". If the error
+// already has a field path, from an earlier call or from an error in its
+// chain, path is prepended to it, so that a validation error for a nested
+// field can be wrapped by each parent in turn to build the full path,
+// e.g. "user.address.zip".
+func (err *Error) WithFieldPath(path string) *Error {
+	located := err.clone()
+	if child := err.FieldPath(); child != "" && path != "" {
+		located.fieldPath = path + "." + child
+	} else if child != "" {
+		located.fieldPath = child
+	} else {
+		located.fieldPath = path
+	}
+	return located
+}
+
+// FieldPath returns the full path of the field the error is about, as built
+// up by WithFieldPath, or "" if it was never called in the error's chain.
+func (err *Error) FieldPath() string {
+	if located := outermost(err, func(e *Error) bool { return e.fieldPath != "" }); located != nil {
+		return located.fieldPath
+	}
+	return ""
+}
@@ -0,0 +1,31 @@
+package errors
+
+import (
+	"io"
+	"testing"
+)
+
+func TestFieldPath(t *testing.T) {
+	if New(io.EOF).FieldPath() != "" {
+		t.Errorf("Errors should have no field path by default")
+	}
+
+	child := New("must be 5 digits").WithFieldPath("zip")
+	if child.FieldPath() != "zip" {
+		t.Errorf("Wrong field path: %s", child.FieldPath())
+	}
+
+	parent := child.WithFieldPath("address")
+	if parent.FieldPath() != "address.zip" || child.FieldPath() != "zip" {
+		t.Errorf("Parent path should be prepended: %s", parent.FieldPath())
+	}
+
+	wrapped := WrapPrefixForce(parent, "invalid user", 0).WithFieldPath("user")
+	if wrapped.FieldPath() != "user.address.zip" {
+		t.Errorf("Parent path should be prepended through the chain: %s", wrapped.FieldPath())
+	}
+
+	if WrapPrefix(parent, "invalid", 0).FieldPath() != "address.zip" {
+		t.Errorf("Wrapping should keep the field path")
+	}
+}
@@ -0,0 +1,29 @@
+package errors
+
+// Freeze returns a copy of the error that is sealed against changes, for
+// errors that are handed across an API boundary. The With* methods and the
+// other builders already leave the error they are called on unchanged and
+// return a new, unfrozen copy, so Freeze mostly documents the contract; but
+// methods that would change a frozen error in place, such as UnmarshalJSON,
+// fail instead. In builds with the errors_debug tag they panic, so that such
+// misuse is found early.
+func (err *Error) Freeze() *Error {
+	frozen := err.clone()
+	frozen.frozen = true
+	return frozen
+}
+
+// IsFrozen reports whether the error was returned by Freeze.
+func (err *Error) IsFrozen() bool {
+	return err.frozen
+}
+
+// mutatedFrozen returns the error for an attempt to change a frozen error
+// with the given method, or panics with it if built with errors_debug.
+func mutatedFrozen(method string) error {
+	err := Errorf("errors: %s called on a frozen error", method)
+	if panicOnFrozen {
+		panic(err)
+	}
+	return err
+}
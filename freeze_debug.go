@@ -0,0 +1,7 @@
+//go:build errors_debug
+// +build errors_debug
+
+package errors
+
+// panicOnFrozen makes changing a frozen error panic rather than fail.
+const panicOnFrozen = true
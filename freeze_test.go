@@ -0,0 +1,36 @@
+package errors
+
+import (
+	"io"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	original := New(io.EOF)
+	frozen := original.Freeze()
+
+	if !frozen.IsFrozen() || original.IsFrozen() || frozen == original {
+		t.Errorf("Freeze should return a frozen copy")
+	}
+
+	coded := frozen.WithCode("eof").AddNote("note")
+	if coded == frozen || coded.IsFrozen() {
+		t.Errorf("With* on a frozen error should return a new error")
+	}
+
+	if Code(frozen) != "" || len(frozen.Notes()) != 0 || frozen.Error() != "EOF" {
+		t.Errorf("With* should not change the frozen error")
+	}
+
+	prefixed := WrapPrefix(frozen, "read", 0)
+	if prefixed == frozen || frozen.Error() != "EOF" {
+		t.Errorf("WrapPrefix should not change the frozen error")
+	}
+
+	if panicOnFrozen {
+		return
+	}
+	if err := frozen.UnmarshalJSON(mustMarshal(t, New("other"))); err == nil || frozen.Error() != "EOF" {
+		t.Errorf("UnmarshalJSON should not change the frozen error: %v", err)
+	}
+}
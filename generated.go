@@ -0,0 +1,72 @@
+package errors
+
+import (
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var generated struct {
+	sync.Mutex
+	patterns atomic.Value // []string
+}
+
+// ExcludeGenerated leaves the frames of generated code, such as protocol
+// buffers or mocks, out of rendered stacks. Each pattern uses the syntax of
+// path.Match. A pattern ending in ".go", e.g. "*.pb.go", is matched against
+// the base name of each frame's file. Any other pattern is matched against
+// the frame's package path and each of its trailing parts, so "mocks" or
+// "*/mocks" excludes packages named mocks. Frames are only excluded when the
+// stack is rendered, by Stack, CompactStack, ErrorStack and the like;
+// StackFrames still returns every frame. This is intended to be called from
+// an init function.
+func ExcludeGenerated(patterns ...string) {
+	generated.Lock()
+	defer generated.Unlock()
+
+	current, _ := generated.patterns.Load().([]string)
+	generated.patterns.Store(append(append([]string(nil), current...), patterns...))
+}
+
+// renderedFrames returns frames without those excluded by ExcludeGenerated
+// and those above the boundary set with SetStackBoundary.
+func renderedFrames(frames []StackFrame) []StackFrame {
+	frames = truncateAtBoundary(frames)
+
+	patterns, _ := generated.patterns.Load().([]string)
+	if len(patterns) == 0 {
+		return frames
+	}
+
+	var kept []StackFrame
+	for _, frame := range frames {
+		if !isGenerated(frame, patterns) {
+			kept = append(kept, frame)
+		}
+	}
+	return kept
+}
+
+func isGenerated(frame StackFrame, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, ".go") {
+			if ok, _ := path.Match(pattern, path.Base(frame.File)); ok {
+				return true
+			}
+			continue
+		}
+
+		for pkg := frame.Package; pkg != ""; {
+			if ok, _ := path.Match(pattern, pkg); ok {
+				return true
+			}
+			slash := strings.Index(pkg, "/")
+			if slash < 0 {
+				break
+			}
+			pkg = pkg[slash+1:]
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestExcludeGenerated(t *testing.T) {
+	defer generated.patterns.Store([]string(nil))
+
+	err := &Error{Err: io.EOF, frames: []StackFrame{
+		{File: "/src/app/api/service.pb.go", LineNumber: 120, Name: "(*client).Get", Package: "example.com/app/api"},
+		{File: "/src/app/mocks/store.go", LineNumber: 30, Name: "(*Store).Load", Package: "example.com/app/mocks"},
+		{File: "/src/app/handler.go", LineNumber: 12, Name: "handle", Package: "example.com/app"},
+	}}
+
+	ExcludeGenerated("*.pb.go")
+	ExcludeGenerated("mocks")
+
+	if err.CompactStack() != "handler.go:12:handle\n" {
+		t.Errorf("Generated frames were not excluded:\n%s", err.CompactStack())
+	}
+
+	if strings.Contains(string(err.Stack()), "service.pb.go") || !strings.Contains(string(err.Stack()), "handler.go") {
+		t.Errorf("Wrong rendered stack:\n%s", err.Stack())
+	}
+
+	if len(err.StackFrames()) != 3 {
+		t.Errorf("StackFrames should not be filtered")
+	}
+
+	generated.patterns.Store([]string{"*/mocks"})
+	if strings.Count(err.CompactStack(), "\n") != 2 {
+		t.Errorf("Package pattern did not exclude the mocks:\n%s", err.CompactStack())
+	}
+}
@@ -0,0 +1,48 @@
+package errors
+
+// Go runs fn in a new goroutine and delivers its result on the returned
+// channel, which is closed afterwards. If fn panics the panic is recovered
+// and delivered as an *Error whose stacktrace points to where the panic
+// happened and for which IsPanic reports true, rather than crashing the
+// program. The error returned by fn is delivered unchanged, including when it
+// is nil.
+func Go(fn func() error) <-chan error {
+	result := make(chan error, 1)
+
+	go func() {
+		defer close(result)
+		defer func() {
+			if r := recover(); r != nil {
+				// skip 1 frame (the deferred function) so the stack
+				// starts at the panic.
+				result <- wrapRecovered(r, 1)
+			}
+		}()
+
+		result <- fn()
+	}()
+
+	return result
+}
+
+// GoWrapped runs fn with g.Go, such as on a *errgroup.Group from
+// golang.org/x/sync/errgroup, so that the error the group reports always has
+// a stacktrace. An error returned by fn is wrapped as Wrap does, and a panic
+// in fn is recovered and returned as for Go. The group is accepted as an
+// interface so that this package does not depend on errgroup.
+func GoWrapped(g interface{ Go(func() error) }, fn func() error) {
+	g.Go(func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				// skip 1 frame (the deferred function) so the stack
+				// starts at the panic.
+				err = wrapRecovered(r, 1)
+			}
+		}()
+
+		if e := fn(); e != nil {
+			return Wrap(e, 0)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,116 @@
+package errors
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGo(t *testing.T) {
+	if err := <-Go(func() error { return io.EOF }); err != io.EOF {
+		t.Errorf("Returned error was not delivered: %v", err)
+	}
+
+	if err := <-Go(func() error { return nil }); err != nil {
+		t.Errorf("Nil error was not delivered: %v", err)
+	}
+
+	err, ok := (<-Go(func() error {
+		c()
+		return nil
+	})).(*Error)
+	if !ok {
+		t.Fatalf("Panic should be delivered as an *Error")
+	}
+
+	if err.Error() != "97" || err.OriginalValue() != 'a' {
+		t.Errorf("Wrong panic value: %v", err.OriginalValue())
+	}
+
+	if !strings.Contains(string(err.Stack()), "panic('a')") {
+		t.Errorf("Stack should contain the panic: %s", err.Stack())
+	}
+}
+
+// group has the same Go and Wait methods as errgroup.Group.
+type group struct {
+	wg   sync.WaitGroup
+	once sync.Once
+	err  error
+}
+
+func (g *group) Go(fn func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := fn(); err != nil {
+			g.once.Do(func() { g.err = err })
+		}
+	}()
+}
+
+func (g *group) Wait() error {
+	g.wg.Wait()
+	return g.err
+}
+
+func TestGoWrapped(t *testing.T) {
+	g := &group{}
+	GoWrapped(g, func() error { return io.EOF })
+
+	err, ok := g.Wait().(*Error)
+	if !ok || err.Err != io.EOF || len(err.StackFrames()) == 0 {
+		t.Fatalf("Returned error should have a stack: %v", g.Wait())
+	}
+
+	g = &group{}
+	GoWrapped(g, func() error {
+		c()
+		return nil
+	})
+
+	err, ok = g.Wait().(*Error)
+	if !ok || !strings.Contains(string(err.Stack()), "panic('a')") {
+		t.Errorf("Panic should be returned with its stack: %v", g.Wait())
+	}
+
+	g = &group{}
+	GoWrapped(g, func() error { return nil })
+	if g.Wait() != nil {
+		t.Errorf("Nil error should stay nil: %v", g.Wait())
+	}
+}
+
+func TestGoIsPanic(t *testing.T) {
+	err := (<-Go(func() error {
+		var m map[string]int
+		m["a"] = 1
+		return nil
+	})).(*Error)
+	if !err.IsPanic() || !strings.Contains(string(err.Stack()), "goroutine_test.go") {
+		t.Errorf("Runtime panic should be a panic: %s", err.ErrorStack())
+	}
+
+	original := New(io.EOF)
+	err = (<-Go(func() error { panic(original) })).(*Error)
+	if !err.IsPanic() || original.IsPanic() || err.Err != io.EOF {
+		t.Errorf("Panic with an *Error should be marked without changing it")
+	}
+
+	if (<-Go(func() error { return New(io.EOF) })).(*Error).IsPanic() {
+		t.Errorf("Returned errors should not be panics")
+	}
+
+	g := &group{}
+	GoWrapped(g, func() error { panic("boom") })
+	if err, ok := g.Wait().(*Error); !ok || !err.IsPanic() {
+		t.Errorf("GoWrapped panic should be a panic: %v", g.Wait())
+	}
+
+	g = &group{}
+	GoWrapped(g, func() error { return io.EOF })
+	if g.Wait().(*Error).IsPanic() {
+		t.Errorf("GoWrapped error should not be a panic")
+	}
+}
@@ -0,0 +1,57 @@
+//go:build !errors_nohtml
+// +build !errors_nohtml
+
+package errors
+
+import (
+	"bytes"
+	"html/template"
+)
+
+// Build with -tags errors_nohtml to leave out HTMLStack and its dependency on
+// html/template.
+
+var htmlStackTemplate = template.Must(template.New("stack").Parse(`<div class="error">
+<p class="error-message"><strong>{{.Type}}</strong> {{.Message}}</p>
+<ol class="error-stack">
+{{range .Frames}}<li><code>{{.File}}:{{.Line}}</code> <span class="error-func">{{.Func}}</span>{{if .Source}}<pre>{{.Source}}</pre>{{end}}</li>
+{{end}}</ol>
+</div>`))
+
+type htmlFrame struct {
+	File   string
+	Line   int
+	Func   string
+	Source string
+}
+
+// HTMLStack renders the error message and callstack as HTML, e.g. for an error
+// page shown during development. Each frame is a list item including its line
+// of source code when it can be read. All text, including the message and the
+// file names, is escaped.
+func (err *Error) HTMLStack() template.HTML {
+	data := struct {
+		Type    string
+		Message string
+		Frames  []htmlFrame
+	}{
+		Type:    err.TypeName(),
+		Message: err.Error(),
+	}
+
+	for _, frame := range renderedFrames(err.StackFrames()) {
+		source, _ := frame.sourceLine()
+		data.Frames = append(data.Frames, htmlFrame{
+			File:   frame.displayFile(),
+			Line:   frame.LineNumber,
+			Func:   frame.Name,
+			Source: source,
+		})
+	}
+
+	buf := bytes.Buffer{}
+	if e := htmlStackTemplate.Execute(&buf, data); e != nil {
+		return template.HTML(template.HTMLEscapeString(err.Error()))
+	}
+	return template.HTML(buf.String())
+}
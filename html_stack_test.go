@@ -0,0 +1,32 @@
+//go:build !errors_nohtml
+// +build !errors_nohtml
+
+package errors
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLStack(t *testing.T) {
+	err := New("<script>alert(1)</script>")
+	err.frames = append(err.StackFrames(), StackFrame{File: "/tmp/<b>evil</b>.go", LineNumber: 1, Name: "x"})
+
+	html := string(err.HTMLStack())
+
+	if strings.Contains(html, "<script>") || strings.Contains(html, "<b>") {
+		t.Errorf("User controlled strings were not escaped: %s", html)
+	}
+
+	if !strings.Contains(html, "&lt;script&gt;alert(1)&lt;/script&gt;") || !strings.Contains(html, "/tmp/&lt;b&gt;evil&lt;/b&gt;.go:1") {
+		t.Errorf("Escaped strings are missing: %s", html)
+	}
+
+	if !strings.Contains(html, "<span class=\"error-func\">TestHTMLStack</span><pre>err := New(&#34;&lt;script&gt;") {
+		t.Errorf("Frame with source line is missing: %s", html)
+	}
+
+	if strings.Count(html, "<li>") != len(err.StackFrames()) {
+		t.Errorf("Not every frame was rendered: %s", html)
+	}
+}
@@ -0,0 +1,53 @@
+package errors
+
+import (
+	"net/http"
+)
+
+// WithHTTPStatus returns a copy of the error with the HTTP status code that
+// should be used when it is returned as the response to a request.
+func (err *Error) WithHTTPStatus(status int) *Error {
+	withStatus := err.clone()
+	withStatus.httpStatus = status
+	return withStatus
+}
+
+// HTTPStatus returns the status to respond with for err: the outermost one
+// set with WithHTTPStatus, so that a handler can override the status chosen
+// by a lower layer, or http.StatusInternalServerError if none was set.
+func HTTPStatus(err error) int {
+	if withStatus := outermost(err, func(e *Error) bool { return e.httpStatus != 0 }); withStatus != nil {
+		return withStatus.httpStatus
+	}
+	return http.StatusInternalServerError
+}
+
+// ProblemDetails returns the error as an RFC 7807 problem details object,
+// ready to be encoded as the body of an HTTP response. The title is the
+// error's TypeName, the status is given by HTTPStatus and the detail is the
+// error's message. The code given by Code is added under "code" if there is
+// one, and the details attached anywhere in the chain are added under
+// "fields", rendered as strings.
+func (err *Error) ProblemDetails() map[string]interface{} {
+	problem := map[string]interface{}{
+		"type":   "about:blank",
+		"title":  err.TypeName(),
+		"status": HTTPStatus(err),
+		"detail": err.Error(),
+	}
+
+	if code := Code(err); code != "" {
+		problem["code"] = code
+	}
+
+	for _, d := range chainDetails(err) {
+		fields, _ := problem["fields"].(map[string]interface{})
+		if fields == nil {
+			fields = map[string]interface{}{}
+			problem["fields"] = fields
+		}
+		fields[d.key] = detailValue(d.value)
+	}
+
+	return problem
+}
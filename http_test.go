@@ -0,0 +1,32 @@
+package errors
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestProblemDetails(t *testing.T) {
+	err := WrapPrefix(New(io.EOF).WithHTTPStatus(http.StatusNotFound).WithCode("not_found"), "load", 0).WithDetail("id", stringer("42"))
+
+	expected := map[string]interface{}{
+		"type":   "about:blank",
+		"title":  "*errors.errorString",
+		"status": http.StatusNotFound,
+		"detail": "load: EOF",
+		"code":   "not_found",
+		"fields": map[string]interface{}{"id": "42"},
+	}
+	if problem := err.ProblemDetails(); !reflect.DeepEqual(problem, expected) {
+		t.Errorf("Wrong problem details: %v", problem)
+	}
+
+	if problem := New(io.EOF).ProblemDetails(); problem["status"] != http.StatusInternalServerError || problem["fields"] != nil || problem["code"] != nil {
+		t.Errorf("Wrong default problem details: %v", problem)
+	}
+
+	if HTTPStatus(io.EOF) != http.StatusInternalServerError || HTTPStatus(New(err)) != http.StatusNotFound {
+		t.Errorf("Wrong HTTP status")
+	}
+}
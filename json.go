@@ -0,0 +1,196 @@
+package errors
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+)
+
+// IncludeBuildInfo makes MarshalJSON add the result of BuildInfo to its
+// output under "build". It is off by default.
+//
+// Deprecated: Use Configure.
+var IncludeBuildInfo = false
+
+// JSONError is the JSON representation of an *Error used by MarshalJSON and
+// UnmarshalJSON. It can be used to decode errors serialized by this package
+// without depending on *Error.
+type JSONError struct {
+	// The message returned by Error
+	Error string `json:"error"`
+	// The type name returned by TypeName
+	Type string `json:"type"`
+	// The stack frames, innermost first
+	Stack []JSONFrame `json:"stack"`
+	// The details attached with WithDetail, rendered as strings
+	Fields map[string]interface{} `json:"fields,omitempty"`
+	// The result of BuildInfo, if IncludeBuildInfo was set
+	Build map[string]string `json:"build,omitempty"`
+	// The payload attached with WithPayload, encoded as base64
+	Payload []byte `json:"payload,omitempty"`
+}
+
+// JSONFrame is the JSON representation of a StackFrame.
+type JSONFrame struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Func    string `json:"func"`
+	Package string `json:"package"`
+}
+
+// MarshalJSON encodes the error as a JSONError, e.g.
+// {"error":"EOF","type":"*errors.errorString","stack":[{"file":...}]}.
+func (err *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(err.toJSON())
+}
+
+// MarshalJSONCompact encodes the error as MarshalJSON does, but without the
+// "stack" key. This makes log entries much smaller when only the message and
+// the fields are needed. The output can still be decoded by UnmarshalJSON,
+// which gives an error with no stack frames.
+func (err *Error) MarshalJSONCompact() ([]byte, error) {
+	out := err.toJSON()
+	return json.Marshal(struct {
+		Error   string                 `json:"error"`
+		Type    string                 `json:"type"`
+		Fields  map[string]interface{} `json:"fields,omitempty"`
+		Build   map[string]string      `json:"build,omitempty"`
+		Payload []byte                 `json:"payload,omitempty"`
+	}{out.Error, out.Type, out.Fields, out.Build, out.Payload})
+}
+
+func (err *Error) toJSON() JSONError {
+	out := JSONError{
+		Error: err.Error(),
+		Type:  err.TypeName(),
+		Stack: []JSONFrame{},
+	}
+
+	for _, frame := range err.StackFrames() {
+		out.Stack = append(out.Stack, JSONFrame{
+			File:    frame.File,
+			Line:    frame.LineNumber,
+			Func:    frame.Name,
+			Package: frame.Package,
+		})
+	}
+
+	for key, value := range err.Details() {
+		if out.Fields == nil {
+			out.Fields = map[string]interface{}{}
+		}
+		out.Fields[key] = detailValue(value)
+	}
+
+	if config().IncludeBuildInfo {
+		out.Build = BuildInfo()
+	}
+
+	out.Payload = err.payload
+	return out
+}
+
+// UnmarshalJSON decodes an error encoded by MarshalJSON. The decoded error has
+// the same message, type name, stack frames and fields, but its underlying
+// error cannot be restored, so Is and As will not match the original cause.
+// It fails if err is frozen.
+func (err *Error) UnmarshalJSON(data []byte) error {
+	if err.frozen {
+		return mutatedFrozen("UnmarshalJSON")
+	}
+
+	var in JSONError
+	if e := json.Unmarshal(data, &in); e != nil {
+		return e
+	}
+
+	frames := make([]StackFrame, len(in.Stack))
+	for i, frame := range in.Stack {
+		frames[i] = StackFrame{
+			File:       frame.File,
+			LineNumber: frame.Line,
+			Name:       frame.Func,
+			Package:    frame.Package,
+		}
+	}
+
+	keys := make([]string, 0, len(in.Fields))
+	for key := range in.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var details []detail
+	for _, key := range keys {
+		details = append(details, detail{key, detailString(fmt.Sprint(in.Fields[key]))})
+	}
+
+	// Start from a fresh Error so that nothing of the one decoded into, such
+	// as whether it was logged, is left over.
+	*err = Error{
+		Err:     decodedError{message: in.Error, typeName: in.Type},
+		frames:  frames,
+		payload: in.Payload,
+		details: details,
+	}
+	return nil
+}
+
+// MarshalCompressed returns the output of MarshalJSON compressed with gzip,
+// which makes errors considerably smaller to send to a remote collector. It
+// can be decoded with UnmarshalCompressed.
+func (err *Error) MarshalCompressed() ([]byte, error) {
+	data, e := err.MarshalJSON()
+	if e != nil {
+		return nil, e
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, e := w.Write(data); e != nil {
+		return nil, e
+	}
+	if e := w.Close(); e != nil {
+		return nil, e
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalCompressed decodes an error encoded by MarshalCompressed, as
+// UnmarshalJSON does.
+func UnmarshalCompressed(data []byte) (*Error, error) {
+	r, e := gzip.NewReader(bytes.NewReader(data))
+	if e != nil {
+		return nil, e
+	}
+
+	decompressed, e := ioutil.ReadAll(r)
+	if e != nil {
+		return nil, e
+	}
+
+	err := &Error{}
+	if e := err.UnmarshalJSON(decompressed); e != nil {
+		return nil, e
+	}
+	return err, nil
+}
+
+// decodedError is the underlying error of an *Error decoded by UnmarshalJSON.
+type decodedError struct {
+	message  string
+	typeName string
+}
+
+func (e decodedError) Error() string {
+	return e.message
+}
+
+type detailString string
+
+func (s detailString) String() string {
+	return string(s)
+}
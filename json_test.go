@@ -0,0 +1,165 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	err := New(io.EOF)
+
+	var decoded map[string]interface{}
+	if e := json.Unmarshal(mustMarshal(t, err), &decoded); e != nil {
+		t.Fatal(e)
+	}
+
+	if decoded["error"] != "EOF" || decoded["type"] != "*errors.errorString" {
+		t.Errorf("Wrong message or type: %v", decoded)
+	}
+
+	stack, ok := decoded["stack"].([]interface{})
+	if !ok || len(stack) != len(err.StackFrames()) {
+		t.Fatalf("Wrong stack: %v", decoded["stack"])
+	}
+
+	top := stack[0].(map[string]interface{})
+	if top["func"] != "TestMarshalJSON" || top["package"] != "github.com/go-errors/errors" {
+		t.Errorf("Wrong top frame: %v", top)
+	}
+
+	if _, ok := decoded["build"]; ok {
+		t.Errorf("Build info should be omitted by default")
+	}
+}
+
+func TestMarshalJSONBuildInfo(t *testing.T) {
+	defer func() { IncludeBuildInfo = false }()
+	IncludeBuildInfo = true
+
+	var decoded struct {
+		Build map[string]string `json:"build"`
+	}
+	if e := json.Unmarshal(mustMarshal(t, New(io.EOF)), &decoded); e != nil {
+		t.Fatal(e)
+	}
+
+	if decoded.Build["go"] != runtime.Version() {
+		t.Errorf("Build info was not included: %v", decoded.Build)
+	}
+
+	info := BuildInfo()
+	info["go"] = "modified"
+	if BuildInfo()["go"] != runtime.Version() {
+		t.Errorf("BuildInfo should return a copy")
+	}
+}
+
+func mustMarshal(t *testing.T, err *Error) []byte {
+	data, e := json.Marshal(err)
+	if e != nil {
+		t.Fatal(e)
+	}
+	return data
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	original := WrapPrefix(io.EOF, "read", 0).WithDetail("file", stringer("config.yml"))
+
+	var decoded Error
+	if e := json.Unmarshal(mustMarshal(t, original), &decoded); e != nil {
+		t.Fatal(e)
+	}
+
+	if decoded.Error() != "read: EOF" || decoded.TypeName() != "*errors.errorString" {
+		t.Errorf("Wrong message or type: %s %s", decoded.Error(), decoded.TypeName())
+	}
+
+	if !reflect.DeepEqual(decoded.Details()["file"], detailString("config.yml")) {
+		t.Errorf("Fields were not decoded: %v", decoded.Details())
+	}
+
+	frames, expected := decoded.StackFrames(), original.StackFrames()
+	if len(frames) != len(expected) {
+		t.Fatalf("Wrong number of frames: %d != %d", len(frames), len(expected))
+	}
+	for i := range frames {
+		if frames[i].File != expected[i].File || frames[i].LineNumber != expected[i].LineNumber || frames[i].Name != expected[i].Name || frames[i].Package != expected[i].Package {
+			t.Errorf("Frame %d does not match: %v != %v", i, frames[i], expected[i])
+		}
+	}
+
+	if !reflect.DeepEqual(mustMarshal(t, &decoded), mustMarshal(t, original)) {
+		t.Errorf("Decoded error does not encode the same")
+	}
+
+	var exported JSONError
+	if e := json.Unmarshal(mustMarshal(t, original), &exported); e != nil {
+		t.Fatal(e)
+	}
+	if exported.Error != "read: EOF" || exported.Fields["file"] != "config.yml" || exported.Stack[0].Func != "TestJSONRoundTrip" {
+		t.Errorf("JSONError did not decode: %v", exported)
+	}
+
+	reused := MarkLogged(New(io.ErrUnexpectedEOF).AddNote("old"))
+	if e := json.Unmarshal(mustMarshal(t, original), reused); e != nil {
+		t.Fatal(e)
+	}
+	if WasLogged(reused) || len(reused.Notes()) != 0 || reused.Error() != "read: EOF" {
+		t.Errorf("Decoding should replace everything in the error: %v %v", WasLogged(reused), reused.Notes())
+	}
+}
+
+func TestCompressedRoundTrip(t *testing.T) {
+	original := WrapPrefix(io.EOF, "read", 0).WithDetail("file", stringer("config.yml"))
+
+	compressed, e := original.MarshalCompressed()
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if len(compressed) >= len(mustMarshal(t, original)) {
+		t.Errorf("Compressed error is not smaller: %d bytes", len(compressed))
+	}
+
+	decoded, e := UnmarshalCompressed(compressed)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if !reflect.DeepEqual(mustMarshal(t, decoded), mustMarshal(t, original)) {
+		t.Errorf("Decoded error does not encode the same")
+	}
+
+	if _, e := UnmarshalCompressed([]byte("{}")); e == nil {
+		t.Errorf("Uncompressed input should be rejected")
+	}
+}
+
+func TestMarshalJSONCompact(t *testing.T) {
+	err := New(io.EOF).WithDetail("file", stringer("config.yml"))
+
+	data, e := err.MarshalJSONCompact()
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	var decoded map[string]interface{}
+	if e := json.Unmarshal(data, &decoded); e != nil {
+		t.Fatal(e)
+	}
+
+	if _, ok := decoded["stack"]; ok {
+		t.Errorf("Compact output should not have a stack: %s", data)
+	}
+
+	if decoded["error"] != "EOF" || decoded["fields"].(map[string]interface{})["file"] != "config.yml" {
+		t.Errorf("Wrong compact output: %s", data)
+	}
+
+	if len(data) >= len(mustMarshal(t, err)) {
+		t.Errorf("Compact output is not smaller")
+	}
+}
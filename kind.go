@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type kind struct {
+	name      string
+	sentinels []error
+}
+
+var kinds struct {
+	sync.Mutex
+	registered atomic.Value // []kind
+}
+
+// RegisterKind registers name as the kind of errors that are, or wrap, any of
+// the given sentinels, so that KindOf can classify them, e.g.
+// RegisterKind("not_found", sql.ErrNoRows). Calling it again with the same
+// name adds more sentinels to that kind. This is intended to be called from
+// init functions.
+func RegisterKind(name string, sentinels ...error) {
+	kinds.Lock()
+	defer kinds.Unlock()
+
+	current, _ := kinds.registered.Load().([]kind)
+	updated := make([]kind, 0, len(current)+1)
+	added := false
+	for _, k := range current {
+		if k.name == name {
+			k.sentinels = append(append([]error(nil), k.sentinels...), sentinels...)
+			added = true
+		}
+		updated = append(updated, k)
+	}
+	if !added {
+		updated = append(updated, kind{name, append([]error(nil), sentinels...)})
+	}
+	kinds.registered.Store(updated)
+}
+
+// KindOf returns the name of the kind registered with RegisterKind for a
+// sentinel that err Is, or "" if there is none. If err matches several kinds
+// the one registered first is returned.
+func KindOf(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	registered, _ := kinds.registered.Load().([]kind)
+	for _, k := range registered {
+		for _, sentinel := range k.sentinels {
+			if Is(err, sentinel) {
+				return k.name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,33 @@
+package errors
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"testing"
+)
+
+func TestKindOf(t *testing.T) {
+	registered, _ := kinds.registered.Load().([]kind)
+	defer kinds.registered.Store(registered)
+
+	RegisterKind("not_found", sql.ErrNoRows)
+	RegisterKind("timeout", context.DeadlineExceeded)
+	RegisterKind("not_found", io.EOF)
+
+	if kind := KindOf(WrapPrefix(sql.ErrNoRows, "load user", 0)); kind != "not_found" {
+		t.Errorf("Wrong kind for a wrapped sentinel: %q", kind)
+	}
+
+	if kind := KindOf(New(wrappingError{"query", context.DeadlineExceeded})); kind != "timeout" {
+		t.Errorf("Wrong kind for a sentinel behind %%w: %q", kind)
+	}
+
+	if kind := KindOf(New(io.EOF)); kind != "not_found" {
+		t.Errorf("Sentinels added later should belong to the kind: %q", kind)
+	}
+
+	if KindOf(New(io.ErrUnexpectedEOF)) != "" || KindOf(nil) != "" {
+		t.Errorf("Unregistered errors should have no kind")
+	}
+}
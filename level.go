@@ -0,0 +1,142 @@
+package errors
+
+import (
+	"runtime"
+)
+
+// A Level is the severity that was intended for an error where it was
+// created. It does not change how the error behaves, but logging
+// integrations can use it to decide how to report the error.
+type Level int
+
+// The levels an error can be given with WithLevel. An error that does not
+// have a level is treated as LevelError.
+const (
+	LevelDebug Level = iota + 1
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+// String returns the lower case name of the level, e.g. "warn".
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	case LevelFatal:
+		return "fatal"
+	}
+	return "unknown"
+}
+
+// CaptureStackMinLevel is the lowest level at which errors have a full
+// stacktrace, so that stacks are not paid for on errors that are only
+// reported as information. New errors are treated as LevelError: if this is
+// at or below LevelError they are created with their full stack, and
+// WithLevel drops it if it lowers the error below this level. If it is above
+// LevelError, new errors only record the frame of the line of code that
+// created them, which is cheap, and WithLevel expands that frame into the
+// full stack if it raises the error to this level or above. As the callers
+// are only known when WithLevel is called, that only works if it is called in
+// the function that created the error, e.g.
+// errors.New(err).WithLevel(errors.LevelFatal); otherwise the error keeps
+// just the frame where it was created. It is 0, which captures every stack in
+// full, by default. It can also be set with Configure.
+var CaptureStackMinLevel Level
+
+// WithLevel returns a copy of the error with the given level attached. The
+// copy's stacktrace may be captured or dropped according to
+// CaptureStackMinLevel.
+func (err *Error) WithLevel(l Level) *Error {
+	leveled := err.clone()
+	leveled.level = l
+
+	cfg := config()
+	if min := cfg.CaptureStackMinLevel; min != 0 {
+		if l < min {
+			if leveled.site == 0 && len(err.stack) > 0 {
+				leveled.site = err.stack[0]
+			}
+			leveled.stack, leveled.repeats = nil, nil
+		} else if err.site != 0 {
+			stack, repeats := captureStack(cfg, 1)
+			leveled.stack, leveled.repeats = expandSite(err.site, stack, repeats, cfg.MaxStackDepth)
+			leveled.site = 0
+			leveled.frames = nil
+		} else if err.stack == nil && err.frames == nil {
+			leveled.stack, leveled.repeats = captureStack(cfg, 1)
+		}
+	}
+
+	return leveled
+}
+
+// expandSite returns the stack of an error created at site, given the stack
+// of a later call in the same goroutine and the number of calls folded into
+// each of its frames, if any. The frame of that call is replaced by site if
+// they are in the same function, as when WithLevel is called where the error
+// was created. Otherwise the callers of site are not known and only site is
+// returned.
+func expandSite(site uintptr, stack []uintptr, repeats []int, depth int) ([]uintptr, []int) {
+	if len(stack) == 0 || !sameFunction(site, stack[0]) {
+		return []uintptr{site}, nil
+	}
+
+	stack = stack[1:]
+	if repeats != nil {
+		repeats = repeats[1:]
+	}
+
+	expanded := append([]uintptr{site}, stack...)
+	if repeats != nil {
+		repeats = append([]int{0}, repeats...)
+	}
+	if len(expanded) > depth {
+		expanded = expanded[:depth]
+		if repeats != nil {
+			repeats = repeats[:depth]
+		}
+	}
+	return expanded, repeats
+}
+
+// sameFunction reports whether the return addresses a and b are in the same
+// function. Names are compared, as a function that was inlined into another
+// shares its entry point.
+func sameFunction(a, b uintptr) bool {
+	fa, fb := runtime.FuncForPC(a-1), runtime.FuncForPC(b-1)
+	return fa != nil && fb != nil && fa.Name() == fb.Name()
+}
+
+// Level returns the level attached to this error with WithLevel, or
+// LevelError if there is none.
+func (err *Error) Level() Level {
+	if err.level == 0 {
+		return LevelError
+	}
+	return err.level
+}
+
+// LevelOf returns the level of the outermost *Error in err's chain that has
+// one attached, or LevelError if there is none.
+func LevelOf(err error) Level {
+	if level := chainLevel(err); level != 0 {
+		return level
+	}
+	return LevelError
+}
+
+// chainLevel is LevelOf, but returns 0 if no level was attached.
+func chainLevel(err error) Level {
+	if leveled := outermost(err, func(e *Error) bool { return e.level != 0 }); leveled != nil {
+		return leveled.level
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+package errors
+
+import (
+	"io"
+	"runtime"
+	"testing"
+)
+
+func TestLevel(t *testing.T) {
+	err := New(io.EOF)
+	if err.Level() != LevelError || LevelOf(err) != LevelError {
+		t.Errorf("Default level should be error")
+	}
+
+	if LevelOf(io.EOF) != LevelError {
+		t.Errorf("Default level of a plain error should be error")
+	}
+
+	warn := err.WithLevel(LevelWarn)
+	if warn.Level() != LevelWarn || LevelOf(warn) != LevelWarn {
+		t.Errorf("Explicit level was not kept")
+	}
+
+	if err.Level() != LevelError {
+		t.Errorf("WithLevel changed the original error")
+	}
+
+	if warn.Error() != err.Error() || !Is(warn, io.EOF) {
+		t.Errorf("Level should not affect the error")
+	}
+
+	wrapped := wrappingError{"context", New(warn)}
+	if LevelOf(wrapped) != LevelWarn {
+		t.Errorf("LevelOf did not find the level in the chain")
+	}
+
+	if LevelOf(New(warn).WithLevel(LevelDebug)) != LevelDebug {
+		t.Errorf("The outermost level should win")
+	}
+
+	if LevelFatal.String() != "fatal" || Level(0).String() != "unknown" {
+		t.Errorf("Wrong level names")
+	}
+}
+
+func newLeveledHelper() *Error {
+	return New(io.EOF)
+}
+
+func TestCaptureStackMinLevel(t *testing.T) {
+	defer func() { CaptureStackMinLevel = 0 }()
+	CaptureStackMinLevel = LevelWarn
+
+	_, _, line, _ := runtime.Caller(0)
+	err := New(io.EOF)
+	frames := err.StackFrames()
+	if len(frames) < 2 || frames[0].LineNumber != line+1 || frames[1].Package != "testing" {
+		t.Errorf("Error without a level should have the full stack: %v", frames)
+	}
+
+	if info := err.WithLevel(LevelInfo); len(info.StackFrames()) != 0 {
+		t.Errorf("Info error should not have a stack")
+	}
+
+	frames = err.WithLevel(LevelError).StackFrames()
+	if len(frames) < 2 || frames[0].LineNumber != line+1 || frames[1].Package != "testing" {
+		t.Errorf("Error error should keep the full stack: %v", frames)
+	}
+
+	frames = err.WithLevel(LevelInfo).WithLevel(LevelFatal).StackFrames()
+	if len(frames) < 2 || frames[0].LineNumber != line+1 || frames[1].Package != "testing" {
+		t.Errorf("Raising the level again should expand the call site: %v", frames)
+	}
+
+	decoded := &Error{Err: io.EOF}
+	if frames := decoded.WithLevel(LevelError).StackFrames(); len(frames) == 0 || frames[0].Name != "TestCaptureStackMinLevel" {
+		t.Errorf("Error without a stack should get one from WithLevel: %v", frames)
+	}
+}
+
+func TestCaptureStackMinLevelFatal(t *testing.T) {
+	defer func() { CaptureStackMinLevel, CaptureFunc = 0, captureCallers }()
+	CaptureStackMinLevel = LevelFatal
+
+	var depths []int
+	CaptureFunc = func(skip int, depth int) []uintptr {
+		depths = append(depths, depth)
+		return captureCallers(1+skip, depth)
+	}
+
+	_, _, line, _ := runtime.Caller(0)
+	err := New(io.EOF)
+	if len(depths) != 1 || depths[0] != 1 {
+		t.Errorf("Only the call site should be captured by New: %v", depths)
+	}
+	if frames := err.StackFrames(); len(frames) != 1 || frames[0].LineNumber != line+1 {
+		t.Errorf("Error without a level should have the call site: %v", frames)
+	}
+
+	if warn := err.WithLevel(LevelWarn); len(warn.StackFrames()) != 0 {
+		t.Errorf("Warn error should not have a stack")
+	}
+
+	frames := err.WithLevel(LevelFatal).StackFrames()
+	if len(frames) < 2 || frames[0].LineNumber != line+1 || frames[1].Package != "testing" {
+		t.Errorf("Fatal error should have the full stack from where it was created: %v", frames)
+	}
+
+	frames = newLeveledHelper().WithLevel(LevelFatal).StackFrames()
+	if len(frames) != 1 || frames[0].Name != "newLeveledHelper" {
+		t.Errorf("Only the call site should be kept when the callers are not known: %v", frames)
+	}
+}
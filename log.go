@@ -0,0 +1,90 @@
+package errors
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Logger, if set, is called by WrapLog to log errors. It is nil by default,
+// in which case WrapLog does not log anything.
+//
+// Deprecated: Use Configure.
+var Logger func(*Error)
+
+// WrapLog wraps the given value as Wrap does and passes the result to Logger,
+// so that an error can be logged where it is returned, e.g.
+// return errors.WrapLog(err, 0). An error is only logged once, so when it is
+// passed through WrapLog at several layers only the first logs it. The skip
+// parameter behaves as for Wrap.
+func WrapLog(e interface{}, skip int) *Error {
+	err := Wrap(e, 1+skip)
+	logger := config().Logger
+	if err == nil || logger == nil || WasLogged(err) {
+		return err
+	}
+
+	if atomic.CompareAndSwapUint32(&err.logged, 0, 1) {
+		logger(err)
+	}
+	return err
+}
+
+// MarkLogged records that err has been logged, so that other layers can
+// check WasLogged and avoid logging it again. If err is not already an *Error
+// it is wrapped as Wrap does, with a stacktrace that points to the line of
+// code that called MarkLogged. The mark is stored on the *Error, so it is
+// kept when the error is wrapped further. MarkLogged returns nil if err is
+// nil.
+func MarkLogged(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	wrapped := Wrap(err, 1)
+	atomic.StoreUint32(&wrapped.logged, 1)
+	return wrapped
+}
+
+// WasLogged reports whether any *Error in err's chain has been logged, either
+// by WrapLog or with MarkLogged.
+func WasLogged(err error) bool {
+	return outermost(err, func(e *Error) bool { return atomic.LoadUint32(&e.logged) != 0 }) != nil
+}
+
+// LogrusFields returns err as fields for a structured logger such as logrus,
+// whose Fields type the result can be passed as without conversion, e.g.
+// log.WithFields(errors.LogrusFields(err)).Error("request failed"). The
+// fields are "error" with the message, "type" with the type name, "stack"
+// with the stacktrace of the outermost *Error in err's chain, and the details
+// and code attached anywhere in the chain. A detail whose key is already used
+// is added with a "fields." prefix instead. It returns nil if err is nil.
+func LogrusFields(err error) map[string]interface{} {
+	if err == nil {
+		return nil
+	}
+
+	fields := map[string]interface{}{
+		"error": err.Error(),
+		"type":  fmt.Sprintf("%T", err),
+	}
+
+	var e *Error
+	if As(err, &e) {
+		fields["type"] = e.TypeName()
+		fields["stack"] = string(e.Stack())
+	}
+	if code := Code(err); code != "" {
+		fields["code"] = code
+	}
+
+	reserved := map[string]bool{"error": true, "type": true, "stack": true, "code": true}
+	for _, d := range chainDetails(err) {
+		if reserved[d.key] {
+			fields["fields."+d.key] = detailValue(d.value)
+		} else {
+			fields[d.key] = detailValue(d.value)
+		}
+	}
+
+	return fields
+}
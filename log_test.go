@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWrapLog(t *testing.T) {
+	defer func() { Logger = nil }()
+
+	if WrapLog(io.EOF, 0).Err != io.EOF {
+		t.Errorf("WrapLog without a Logger should still wrap")
+	}
+
+	var logged []*Error
+	Logger = func(err *Error) {
+		logged = append(logged, err)
+	}
+
+	err := WrapLog(io.EOF, 0)
+	if len(logged) != 1 || logged[0] != err {
+		t.Fatalf("Error was not logged")
+	}
+
+	if frame, _ := err.TopFrame(); frame.Name != "TestWrapLog" {
+		t.Errorf("Stack should start at the call to WrapLog: %s", frame.Name)
+	}
+
+	if WrapLog(err, 0) != err || len(logged) != 1 {
+		t.Errorf("Error was logged twice")
+	}
+
+	if WrapLog(WrapPrefix(err, "outer", 0), 0); len(logged) != 1 {
+		t.Errorf("Prefixed error was logged again")
+	}
+
+	if WrapLog(New(err), 0); len(logged) != 1 {
+		t.Errorf("Error wrapping a logged error was logged again")
+	}
+
+	if WrapLog(nil, 0) != nil || len(logged) != 1 {
+		t.Errorf("Nil should not be logged")
+	}
+}
+
+func TestMarkLogged(t *testing.T) {
+	if WasLogged(io.EOF) || WasLogged(nil) || MarkLogged(nil) != nil {
+		t.Errorf("Plain errors are not logged")
+	}
+
+	err := New(io.EOF)
+	if WasLogged(err) {
+		t.Errorf("New error should not be logged")
+	}
+
+	if MarkLogged(err) != err || !WasLogged(err) {
+		t.Errorf("MarkLogged should mark the *Error itself")
+	}
+
+	if !WasLogged(Wrap(err, 0)) || !WasLogged(New(err)) || !WasLogged(WrapPrefix(err, "outer", 0)) {
+		t.Errorf("Mark was lost by further wrapping")
+	}
+
+	marked := MarkLogged(io.EOF)
+	if marked.Err != io.EOF || !WasLogged(marked) {
+		t.Errorf("Plain error was not wrapped and marked")
+	}
+
+	if frame, _ := marked.TopFrame(); frame.Name != "TestMarkLogged" {
+		t.Errorf("Stack should start at the call to MarkLogged: %s", frame.Name)
+	}
+}
+
+func TestLogrusFields(t *testing.T) {
+	inner := New(io.EOF).WithDetail("file", stringer("config.yml")).WithCode("eof")
+	err := WrapPrefix(inner, "load", 0).WithDetail("type", stringer("yaml"))
+
+	fields := LogrusFields(err)
+	if fields["error"] != "load: EOF" || fields["type"] != "*errors.errorString" || fields["code"] != "eof" {
+		t.Errorf("Wrong fields: %v", fields)
+	}
+
+	if fields["stack"] != string(err.Stack()) || !strings.Contains(fields["stack"].(string), "TestLogrusFields") {
+		t.Errorf("Fields should contain the stack: %v", fields["stack"])
+	}
+
+	if fields["file"] != "config.yml" || fields["fields.type"] != "yaml" {
+		t.Errorf("Fields should contain the details: %v", fields)
+	}
+
+	if fields := LogrusFields(io.EOF); fields["type"] != "*errors.errorString" || fields["stack"] != nil {
+		t.Errorf("Wrong fields for a plain error: %v", fields)
+	}
+
+	if LogrusFields(nil) != nil {
+		t.Errorf("Fields of nil should be nil")
+	}
+}
@@ -0,0 +1,87 @@
+package errors
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+)
+
+// MaxAnnotations is the maximum number of notes, of details and of operations
+// added with WithOp that an error keeps, so that an error annotated again on
+// every pass of a retry loop cannot grow without bound. Once an error has that
+// many, further notes, operations and details with new keys are dropped, and
+// a single "..." note is added to show that something is missing. A detail
+// that replaces one with the same key is always kept. 0, the default, keeps
+// everything. It can also be set with Configure.
+var MaxAnnotations = 0
+
+// droppedNote is the note added when annotations are dropped because of
+// MaxAnnotations.
+const droppedNote = "..."
+
+// AddNote returns a copy of the error with note added to its notes: freeform
+// annotations collected as the error propagates, e.g. "while retrying 3/5".
+// Unlike a prefix, a note does not change the message; notes are listed below
+// it by ErrorStack, DebugString and the %+v verb, in the order they were
+// added.
+func (err *Error) AddNote(note string) *Error {
+	noted := err.clone()
+	if max := config().MaxAnnotations; max > 0 && len(err.notes) >= max {
+		noted.notes = withDroppedNote(err.notes)
+	} else {
+		noted.notes = append(append([]string(nil), err.notes...), note)
+	}
+	return noted
+}
+
+// Breadcrumb returns err with a note giving the file and line of the code
+// that called Breadcrumb, e.g. "at handler.go:42", added as AddNote does.
+// Calling it where an error is passed on leaves a cheap trail of the path
+// that the error took, without capturing a new stacktrace. If err is not an
+// *Error it is wrapped as Wrap does. Breadcrumb returns nil if err is nil.
+func Breadcrumb(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	e, ok := err.(*Error)
+	if !ok {
+		e = Wrap(err, 1)
+	}
+
+	if _, file, line, ok := runtime.Caller(1); ok {
+		return e.AddNote(fmt.Sprintf("at %s:%d", filepath.Base(file), line))
+	}
+	return e
+}
+
+// withDroppedNote returns notes followed by droppedNote, unless it already
+// ends with it.
+func withDroppedNote(notes []string) []string {
+	if len(notes) > 0 && notes[len(notes)-1] == droppedNote {
+		return notes
+	}
+	return append(append([]string(nil), notes...), droppedNote)
+}
+
+// Notes returns the notes added with AddNote to every *Error in err's chain,
+// in the order they were added, so notes from inner errors come first.
+func (err *Error) Notes() []string {
+	layers := layersOf(err)
+
+	var notes []string
+	for i := len(layers) - 1; i >= 0; i-- {
+		notes = append(notes, layers[i].notes...)
+	}
+	return notes
+}
+
+// renderNotes returns the notes as lines of "- note", each preceded by a
+// newline.
+func renderNotes(notes []string) string {
+	var str string
+	for _, note := range notes {
+		str += "\n- " + note
+	}
+	return str
+}
@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNotes(t *testing.T) {
+	inner := New(io.EOF).AddNote("reading config.yml")
+	outer := WrapPrefix(New(wrappingError{"load", inner}).AddNote("attempt 1"), "startup", 0).AddNote("attempt 2")
+
+	if notes := outer.Notes(); !reflect.DeepEqual(notes, []string{"reading config.yml", "attempt 1", "attempt 2"}) {
+		t.Errorf("Wrong notes: %v", notes)
+	}
+
+	if outer.Error() != "startup: load: EOF" || len(New(io.EOF).Notes()) != 0 {
+		t.Errorf("Notes should not change the message: %s", outer.Error())
+	}
+
+	if !strings.HasPrefix(inner.ErrorStack(), "*errors.errorString EOF\n- reading config.yml\n") {
+		t.Errorf("Notes are missing from ErrorStack: %s", inner.ErrorStack())
+	}
+
+	if !strings.Contains(outer.DebugString(), "\n- reading config.yml\n- attempt 1\n- attempt 2\n") {
+		t.Errorf("Notes are missing from DebugString: %s", outer.DebugString())
+	}
+
+	if s := fmt.Sprintf("%+v", outer); s != "startup: load: EOF\n- reading config.yml\n- attempt 1\n- attempt 2" {
+		t.Errorf("Notes are missing from %%+v: %q", s)
+	}
+
+	if s := fmt.Sprintf("%v|%s|%q|%12s", inner, inner, inner, New(io.EOF)); s != `EOF|EOF|"EOF"|         EOF` {
+		t.Errorf("Other verbs should format the message: %q", s)
+	}
+
+	if s := fmt.Sprintf("%#v", inner); !strings.HasPrefix(s, "&errors.Error{Err:") {
+		t.Errorf("%%#v should write the struct: %s", s)
+	}
+}
+
+func TestMaxAnnotations(t *testing.T) {
+	defer func() { MaxAnnotations = 0 }()
+	MaxAnnotations = 3
+
+	err := New(io.EOF)
+	for i := 0; i < 100; i++ {
+		err = err.AddNote(fmt.Sprintf("retry %d", i)).WithDetail(fmt.Sprintf("key%d", i), stringer("v")).WithDetail("attempt", stringer(fmt.Sprint(i)))
+	}
+
+	notes := err.Notes()
+	if !reflect.DeepEqual(notes, []string{"retry 0", "retry 1", "retry 2", "..."}) {
+		t.Errorf("Notes should be capped with a single sentinel: %v", notes)
+	}
+
+	details := err.Details()
+	if len(err.details) != 3 || len(details) != 3 || details["attempt"].String() != "99" {
+		t.Errorf("Details should be capped, but replacements kept: %v", details)
+	}
+
+	if _, ok := details["key2"]; ok {
+		t.Errorf("Details past the cap should be dropped: %v", details)
+	}
+}
+
+func TestBreadcrumb(t *testing.T) {
+	err := New(io.EOF)
+	_, _, line, _ := runtime.Caller(0)
+	crumbs := Breadcrumb(Breadcrumb(err))
+
+	expected := fmt.Sprintf("at note_test.go:%d", line+1)
+	if !reflect.DeepEqual(crumbs.Notes(), []string{expected, expected}) {
+		t.Errorf("Wrong breadcrumbs: %v", crumbs.Notes())
+	}
+
+	crumbs = Breadcrumb(breadcrumbHelper(err))
+	if notes := crumbs.Notes(); len(notes) != 2 || notes[0] == notes[1] || !strings.HasPrefix(notes[0], "at note_test.go:") {
+		t.Errorf("Breadcrumbs should be in the order they were added: %v", notes)
+	}
+
+	if crumbs.stack[0] != err.stack[0] {
+		t.Errorf("Breadcrumb should keep the stack")
+	}
+
+	if Breadcrumb(io.EOF).Err != io.EOF || Breadcrumb(nil) != nil {
+		t.Errorf("Breadcrumb with an error or nil failed")
+	}
+}
+
+func breadcrumbHelper(err error) error {
+	return Breadcrumb(err)
+}
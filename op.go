@@ -0,0 +1,27 @@
+package errors
+
+// WithOp returns a copy of the error with the name of the operation that
+// failed attached, such as "store.LoadUser", in the style of the Op field of
+// upstream Go error types. Naming the operation at each layer that an error
+// passes through gives a readable trace of what the program was doing, in
+// terms of its own operations rather than of Go functions. Operations may be
+// dropped once there are MaxAnnotations of them.
+func (err *Error) WithOp(op string) *Error {
+	named := err.clone()
+	if max := config().MaxAnnotations; max > 0 && len(err.ops) >= max {
+		named.notes = withDroppedNote(err.notes)
+	} else {
+		named.ops = append([]string{op}, err.ops...)
+	}
+	return named
+}
+
+// Ops returns the operations attached with WithOp to every *Error in the
+// error's chain, outermost first.
+func (err *Error) Ops() []string {
+	var ops []string
+	for _, layer := range layersOf(err) {
+		ops = append(ops, layer.ops...)
+	}
+	return ops
+}
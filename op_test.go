@@ -0,0 +1,49 @@
+package errors
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOps(t *testing.T) {
+	if len(New(io.EOF).Ops()) != 0 {
+		t.Errorf("Errors should have no ops by default")
+	}
+
+	query := New(io.EOF).WithOp("db.Query")
+	load := WrapPrefixForce(query, "load user", 0).WithOp("store.LoadUser")
+	get := New(wrappingError{"get user", load}).WithOp("api.GetUser")
+
+	expected := []string{"api.GetUser", "store.LoadUser", "db.Query"}
+	if !reflect.DeepEqual(get.Ops(), expected) {
+		t.Errorf("Wrong ops: %v", get.Ops())
+	}
+
+	if !reflect.DeepEqual(query.Ops(), []string{"db.Query"}) {
+		t.Errorf("WithOp changed the inner error: %v", query.Ops())
+	}
+
+	if lines := strings.Split(get.DebugString(), "\n"); lines[1] != "ops: api.GetUser > store.LoadUser > db.Query" {
+		t.Errorf("DebugString should contain the ops: %s", lines[1])
+	}
+}
+
+func TestOpsMaxAnnotations(t *testing.T) {
+	defer func() { MaxAnnotations = 0 }()
+	MaxAnnotations = 2
+
+	err := New(io.EOF)
+	for _, op := range []string{"dial", "retry", "retry", "retry"} {
+		err = err.WithOp(op)
+	}
+
+	if !reflect.DeepEqual(err.Ops(), []string{"retry", "dial"}) {
+		t.Errorf("Ops should be capped: %v", err.Ops())
+	}
+
+	if !reflect.DeepEqual(err.Notes(), []string{"..."}) {
+		t.Errorf("Dropped ops should be marked with a single sentinel: %v", err.Notes())
+	}
+}
@@ -73,16 +73,84 @@ func ParsePanic(text string) (*Error, error) {
 	return nil, Errorf("could not parse panic: %v", text)
 }
 
+// WrapPanic makes an Error from a value recovered from a panic and the
+// stacktrace of the panicking goroutine, as returned by runtime/debug.Stack
+// in the deferred function that recovered it:
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			err = errors.WrapPanic(r, debug.Stack())
+//		}
+//	}()
+//
+// The recovered value is used as the underlying error as New does, so an
+// error that was panicked with can still be matched with Is and As. The
+// stack frames are parsed from stackText and start where panic was called,
+// leaving out the frames of the deferred function. If stackText cannot be
+// parsed, the stacktrace points to the line of code that called WrapPanic.
+// WrapPanic returns nil if recovered is nil.
+func WrapPanic(recovered interface{}, stackText []byte) *Error {
+	if recovered == nil {
+		return nil
+	}
+
+	text := string(stackText)
+	if !strings.HasPrefix(text, "panic: ") {
+		text = "panic: \n\n" + text
+	}
+
+	parsed, e := ParsePanic(text)
+	if e != nil || len(parsed.frames) == 0 {
+		return wrapRecovered(recovered, 1)
+	}
+
+	frames := parsed.frames
+	for i := len(frames) - 1; i >= 0; i-- {
+		if (frames[i].Package == "" || frames[i].Package == "runtime") && (frames[i].Name == "panic" || frames[i].Name == "gopanic") {
+			frames = frames[i+1:]
+			break
+		}
+	}
+
+	err := errorFromValue(config(), recovered)
+	err.frames = frames
+	err.panicked = true
+	return record(err)
+}
+
+// wrapRecovered wraps a value recovered from a panic as Wrap does, with a
+// stacktrace that starts skip frames above the caller of wrapRecovered, and
+// marks it as a panic for IsPanic. A recovered *Error keeps its stacktrace,
+// and is copied so that it is not modified.
+func wrapRecovered(recovered interface{}, skip int) *Error {
+	var err *Error
+	if e, ok := recovered.(*Error); ok {
+		err = e.clone()
+	} else {
+		err = newError(config(), recovered, 1+skip)
+	}
+	err.panicked = true
+	return record(err)
+}
+
 // The lines we're passing look like this:
 //
 //     main.(*foo).destruct(0xc208067e98)
 //             /0/go/src/github.com/bugsnag/bugsnag-go/pan/main.go:22 +0x151
 func parsePanicFrame(name string, line string, createdBy bool) (*StackFrame, error) {
-	idx := strings.LastIndex(name, "(")
-	if idx == -1 && !createdBy {
+	idx := -1
+	if createdBy {
+		// The creator has no arguments, but since go1.21 it is followed by
+		// the goroutine that ran the go statement.
+		if i := strings.LastIndex(name, " in goroutine "); i != -1 {
+			name = name[:i]
+		}
+	} else if idx = strings.LastIndex(name, "("); idx == -1 {
 		return nil, Errorf("bugsnag.panicParser: Invalid line (no call): %s", name)
 	}
+	args := ""
 	if idx != -1 {
+		args = strings.TrimSuffix(name[idx+1:], ")")
 		name = name[:idx]
 	}
 	pkg := ""
@@ -123,5 +191,6 @@ func parsePanicFrame(name string, line string, createdBy bool) (*StackFrame, err
 		LineNumber: int(lno),
 		Package:    pkg,
 		Name:       name,
+		args:       args,
 	}, nil
 }
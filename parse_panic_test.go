@@ -1,7 +1,9 @@
 package errors
 
 import (
+	"io"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -95,9 +97,9 @@ net/http.(*Server).Serve(0xc20806c780, 0x910c88, 0xc20803e168, 0x0, 0x0)
 `
 
 var result = []StackFrame{
-	StackFrame{File: "/0/c/go/src/pkg/runtime/panic.c", LineNumber: 279, Name: "panic", Package: "runtime"},
+	StackFrame{File: "/0/c/go/src/pkg/runtime/panic.c", LineNumber: 279, Name: "panic", Package: "runtime", args: "0x35ce40, 0xc208039db0"},
 	StackFrame{File: "/0/go/src/github.com/loopj/bugsnag-example-apps/go/revelapp/app/controllers/app.go", LineNumber: 13, Name: "func.001", Package: "github.com/loopj/bugsnag-example-apps/go/revelapp/app/controllers"},
-	StackFrame{File: "/0/c/go/src/pkg/net/http/server.go", LineNumber: 1698, Name: "(*Server).Serve", Package: "net/http"},
+	StackFrame{File: "/0/c/go/src/pkg/net/http/server.go", LineNumber: 1698, Name: "(*Server).Serve", Package: "net/http", args: "0xc20806c780, 0x910c88, 0xc20803e168, 0x0, 0x0"},
 }
 
 var resultCreatedBy = append(result,
@@ -140,3 +142,103 @@ func TestParsePanic(t *testing.T) {
 		}
 	}
 }
+
+func TestParsePanicArgs(t *testing.T) {
+	Err, err := ParsePanic(createdBy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frames := Err.StackFrames()
+	if frames[0].Args() != "0x35ce40, 0xc208039db0" {
+		t.Errorf("Wrong args: %q", frames[0].Args())
+	}
+
+	if frames[1].Args() != "" || frames[3].Args() != "" {
+		t.Errorf("Frames without args should have none: %q %q", frames[1].Args(), frames[3].Args())
+	}
+
+	if New("foo").StackFrames()[0].Args() != "" {
+		t.Errorf("Captured frames should have no args")
+	}
+}
+
+func TestParsePanicCreatedByGoroutine(t *testing.T) {
+	Err, err := ParsePanic(strings.Replace(createdBy, "controllers.App.Index\n", "controllers.App.Index in goroutine 7\n", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(Err.StackFrames(), resultCreatedBy) {
+		t.Errorf("Goroutine of the creator should be ignored: %#v", Err.StackFrames())
+	}
+}
+
+func TestIsPanic(t *testing.T) {
+	Err, err := ParsePanic(createdBy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !Err.IsPanic() {
+		t.Errorf("Parsed panic should be a panic")
+	}
+
+	var decoded Error
+	if err := decoded.UnmarshalJSON(mustMarshal(t, Err)); err != nil || !decoded.IsPanic() {
+		t.Errorf("Decoded panic should be a panic: %v", err)
+	}
+
+	if New("panic").IsPanic() || Errorf("hello!").IsPanic() {
+		t.Errorf("Other errors should not be panics")
+	}
+}
+
+var recoveredStack = `goroutine 1 [running]:
+runtime/debug.Stack()
+	/usr/lib/go/src/runtime/debug/stack.go:26 +0x5e
+main.handler.func1()
+	/app/main.go:12 +0x45
+panic({0x4b8d20?, 0x52e7a0?})
+	/usr/lib/go/src/runtime/panic.go:785 +0x132
+main.parse(...)
+	/app/main.go:20
+main.handler()
+	/app/main.go:15 +0x52
+main.main()
+	/app/main.go:30 +0x13
+`
+
+func TestWrapPanic(t *testing.T) {
+	err := WrapPanic(io.EOF, []byte(recoveredStack))
+	if err.Err != io.EOF || !Is(err, io.EOF) {
+		t.Errorf("Recovered error should be the underlying error: %v", err.Err)
+	}
+
+	expected := []StackFrame{
+		{File: "/app/main.go", LineNumber: 20, Name: "parse", Package: "main", args: "..."},
+		{File: "/app/main.go", LineNumber: 15, Name: "handler", Package: "main"},
+		{File: "/app/main.go", LineNumber: 30, Name: "main", Package: "main"},
+	}
+	if !reflect.DeepEqual(err.StackFrames(), expected) {
+		t.Errorf("Stack should start at the panic: %#v", err.StackFrames())
+	}
+
+	value := WrapPanic('a', []byte(recoveredStack))
+	if value.Error() != "97" || value.OriginalValue() != 'a' || len(value.StackFrames()) != 3 {
+		t.Errorf("Recovered value should be wrapped: %v", value)
+	}
+
+	unparsed := WrapPanic("boom", []byte("not a stack"))
+	if frame, _ := unparsed.TopFrame(); unparsed.Error() != "boom" || frame.Name != "TestWrapPanic" {
+		t.Errorf("Unparsed stack should fall back to the caller: %s", frame.Name)
+	}
+
+	if !err.IsPanic() || !value.IsPanic() || !unparsed.IsPanic() {
+		t.Errorf("WrapPanic should make panics")
+	}
+
+	if WrapPanic(nil, nil) != nil {
+		t.Errorf("Nil recovered value should be nil")
+	}
+}
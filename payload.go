@@ -0,0 +1,26 @@
+package errors
+
+// WithPayload returns a copy of the error with the raw bytes that caused it
+// attached, such as a malformed protocol frame. The payload is not part of
+// the message, as it is usually binary, but MarshalJSON includes it encoded
+// as base64. b is copied, so it may be reused by the caller.
+func (err *Error) WithPayload(b []byte) *Error {
+	withPayload := err.clone()
+	withPayload.payload = append([]byte(nil), b...)
+	return withPayload
+}
+
+// Payload returns the payload attached to this error with WithPayload, or
+// nil if there is none. It must not be modified.
+func (err *Error) Payload() []byte {
+	return err.payload
+}
+
+// Payload returns the payload of the outermost *Error in err's chain that
+// was given one with WithPayload, or nil if no error in the chain was.
+func Payload(err error) []byte {
+	if withPayload := outermost(err, func(e *Error) bool { return e.payload != nil }); withPayload != nil {
+		return withPayload.payload
+	}
+	return nil
+}
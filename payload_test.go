@@ -0,0 +1,40 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestPayload(t *testing.T) {
+	frame := []byte{0x00, 0xff, 0x10}
+	err := New(io.ErrUnexpectedEOF).WithPayload(frame)
+	frame[0] = 0x01
+
+	if !bytes.Equal(err.Payload(), []byte{0x00, 0xff, 0x10}) {
+		t.Errorf("Payload was not copied: %v", err.Payload())
+	}
+
+	if strings.Contains(err.Error(), "\xff") {
+		t.Errorf("Payload should not be in the message")
+	}
+
+	if !bytes.Equal(Payload(New(wrappingError{"decode", err})), err.Payload()) || Payload(io.EOF) != nil {
+		t.Errorf("Payload was not found in the chain")
+	}
+
+	data := mustMarshal(t, err)
+	if !strings.Contains(string(data), `"payload":"AP8Q"`) {
+		t.Errorf("Payload is missing from MarshalJSON: %s", data)
+	}
+
+	var decoded Error
+	if e := json.Unmarshal(data, &decoded); e != nil {
+		t.Fatal(e)
+	}
+	if !bytes.Equal(decoded.Payload(), err.Payload()) {
+		t.Errorf("Payload was not decoded: %v", decoded.Payload())
+	}
+}
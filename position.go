@@ -0,0 +1,27 @@
+package errors
+
+// inputPosition is a line and column in the input of a parser.
+type inputPosition struct {
+	line, col int
+}
+
+// WithPosition returns a copy of the error with a position in the input that
+// was being parsed attached, for tools that report errors in user-supplied
+// files or expressions. This is independent of the stacktrace, whose files and
+// lines are in the Go source of the program. The position is kept when the
+// error is wrapped further.
+func (err *Error) WithPosition(line, col int) *Error {
+	positioned := err.clone()
+	positioned.position = &inputPosition{line, col}
+	return positioned
+}
+
+// Position returns the input position of the error, taken from the outermost
+// call to WithPosition in its chain. ok is false if it was never called.
+func (err *Error) Position() (line, col int, ok bool) {
+	positioned := outermost(err, func(e *Error) bool { return e.position != nil })
+	if positioned == nil {
+		return 0, 0, false
+	}
+	return positioned.position.line, positioned.position.col, true
+}
@@ -0,0 +1,31 @@
+package errors
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPosition(t *testing.T) {
+	err := New("unexpected '}'")
+	if _, _, ok := err.Position(); ok {
+		t.Errorf("Errors should have no position by default")
+	}
+
+	positioned := err.WithPosition(3, 14)
+	if line, col, ok := positioned.Position(); !ok || line != 3 || col != 14 {
+		t.Errorf("Wrong position: %d %d %v", line, col, ok)
+	}
+
+	if _, _, ok := err.Position(); ok {
+		t.Errorf("WithPosition changed the original error")
+	}
+
+	wrapped := WrapPrefixForce(positioned, "config.yml", 0)
+	if line, col, ok := wrapped.Position(); !ok || line != 3 || col != 14 {
+		t.Errorf("Position should be found through the chain: %d %d %v", line, col, ok)
+	}
+
+	if lines := strings.Split(wrapped.DebugString(), "\n"); lines[1] != "at line 3 col 14" {
+		t.Errorf("DebugString should contain the position: %s", lines[1])
+	}
+}
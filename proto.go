@@ -0,0 +1,57 @@
+package errors
+
+// ProtoMessage is the method set of a generated protocol buffer message in
+// the original Go protobuf API, the same as github.com/golang/protobuf's
+// proto.Message and google.golang.org/protobuf's protoadapt.MessageV1. It is
+// declared here so that this package does not depend on protobuf.
+//
+// Messages generated by either version of protoc-gen-go implement it, so a
+// value of a concrete message type, such as *errdetails.ErrorInfo, can be
+// passed to WithDetailProto as it is. A value typed as the current
+// google.golang.org/protobuf/proto.Message, such as the result of
+// anypb.UnmarshalNew, has to be converted first:
+//
+//	err = err.WithDetailProto(protoadapt.MessageV1Of(msg))
+//
+// and a message returned by DetailProtos is converted back with
+// protoadapt.MessageV2Of, or with a type assertion to the concrete type.
+type ProtoMessage interface {
+	Reset()
+	String() string
+	ProtoMessage()
+}
+
+// WithDetailProto returns a copy of the error with msg attached as a typed
+// detail, such as a google.rpc.ErrorInfo, so that a gRPC interceptor can pass
+// it on in the status details of the response. The detail is kept when the
+// error is wrapped further. See ProtoMessage for passing a message typed as
+// google.golang.org/protobuf/proto.Message.
+func (err *Error) WithDetailProto(msg ProtoMessage) *Error {
+	detailed := err.clone()
+	detailed.protos = append(append([]ProtoMessage(nil), err.protos...), msg)
+	return detailed
+}
+
+// DetailProtos returns the messages attached with WithDetailProto to every
+// *Error in the error's chain, in the order they were attached, so messages
+// from inner errors come first.
+//
+// The messages can be given to status.WithDetails of google.golang.org/grpc,
+// which takes protoadapt.MessageV1, once they are copied into a slice of that
+// type:
+//
+//	protos := err.DetailProtos()
+//	details := make([]protoadapt.MessageV1, 0, len(protos))
+//	for _, msg := range protos {
+//		details = append(details, msg)
+//	}
+//	st, _ = st.WithDetails(details...)
+func (err *Error) DetailProtos() []ProtoMessage {
+	layers := layersOf(err)
+
+	var protos []ProtoMessage
+	for i := len(layers) - 1; i >= 0; i-- {
+		protos = append(protos, layers[i].protos...)
+	}
+	return protos
+}
@@ -0,0 +1,36 @@
+package errors
+
+import (
+	"io"
+	"testing"
+)
+
+// errorInfo has the methods of a generated google.rpc.ErrorInfo message.
+type errorInfo struct{ reason string }
+
+func (m *errorInfo) Reset()         { *m = errorInfo{} }
+func (m *errorInfo) String() string { return "reason:" + m.reason }
+func (*errorInfo) ProtoMessage()    {}
+
+func TestDetailProtos(t *testing.T) {
+	if len(New(io.EOF).DetailProtos()) != 0 {
+		t.Errorf("Errors should have no detail messages by default")
+	}
+
+	info := &errorInfo{"QUOTA_EXCEEDED"}
+	inner := New(io.EOF).WithDetailProto(info)
+	outer := WrapPrefixForce(inner, "call", 0).WithDetailProto(&errorInfo{"RETRY"})
+
+	protos := outer.DetailProtos()
+	if len(protos) != 2 || protos[0] != info || protos[1].String() != "reason:RETRY" {
+		t.Errorf("Wrong detail messages: %v", protos)
+	}
+
+	if got, ok := inner.DetailProtos()[0].(*errorInfo); !ok || got.reason != "QUOTA_EXCEEDED" {
+		t.Errorf("Detail message should keep its type: %v", got)
+	}
+
+	if len(inner.DetailProtos()) != 1 {
+		t.Errorf("WithDetailProto changed the inner error")
+	}
+}
@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// recent is a fixed size ring of the most recently created errors. It is
+// only written to while recentEnabled is non-zero.
+var recent struct {
+	sync.Mutex
+	errors []*Error
+	next   int
+	full   bool
+}
+
+var recentEnabled int32
+
+// EnableRecentErrors keeps the n most recently created errors in memory so
+// that they can be retrieved with RecentErrors, for example to serve a debug
+// page. Once n errors have been kept the oldest one is overwritten, so memory
+// use stays bounded. Calling EnableRecentErrors with n <= 0 disables this,
+// which is the default, and discards any errors that were kept.
+func EnableRecentErrors(n int) {
+	recent.Lock()
+	defer recent.Unlock()
+
+	if n <= 0 {
+		recent.errors = nil
+		atomic.StoreInt32(&recentEnabled, 0)
+	} else {
+		recent.errors = make([]*Error, n)
+		atomic.StoreInt32(&recentEnabled, 1)
+	}
+	recent.next = 0
+	recent.full = false
+}
+
+// RecentErrors returns the errors kept since EnableRecentErrors was called,
+// oldest first. It returns nil if recording is disabled.
+func RecentErrors() []*Error {
+	recent.Lock()
+	defer recent.Unlock()
+
+	if recent.errors == nil {
+		return nil
+	}
+
+	if !recent.full {
+		return append([]*Error(nil), recent.errors[:recent.next]...)
+	}
+
+	errs := make([]*Error, 0, len(recent.errors))
+	errs = append(errs, recent.errors[recent.next:]...)
+	return append(errs, recent.errors[:recent.next]...)
+}
+
+// record adds a newly created error to the ring of recent errors and returns
+// it, so constructors can call it on their result.
+func record(err *Error) *Error {
+	if atomic.LoadInt32(&recentEnabled) == 0 {
+		return err
+	}
+
+	recent.Lock()
+	defer recent.Unlock()
+
+	if recent.errors != nil {
+		recent.errors[recent.next] = err
+		recent.next++
+		if recent.next == len(recent.errors) {
+			recent.next = 0
+			recent.full = true
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,58 @@
+package errors
+
+import (
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestRecentErrors(t *testing.T) {
+	defer EnableRecentErrors(0)
+
+	New("ignored")
+	if RecentErrors() != nil {
+		t.Errorf("Errors were recorded while disabled")
+	}
+
+	EnableRecentErrors(3)
+
+	first := New("first")
+	Wrap(first, 0)
+	if errs := RecentErrors(); len(errs) != 1 || errs[0] != first {
+		t.Errorf("Wrap of an existing *Error should not be recorded again: %v", errs)
+	}
+
+	second := Errorf("second")
+	third := WrapPrefix(io.EOF, "third", 0)
+	fourth := Wrap("fourth", 0)
+
+	errs := RecentErrors()
+	if len(errs) != 3 || errs[0] != second || errs[1] != third || errs[2] != fourth {
+		t.Errorf("Ring did not keep the most recent errors in order: %v", errs)
+	}
+
+	EnableRecentErrors(0)
+	if RecentErrors() != nil {
+		t.Errorf("Disabling did not discard recorded errors")
+	}
+}
+
+func TestRecentErrorsConcurrent(t *testing.T) {
+	defer EnableRecentErrors(0)
+	EnableRecentErrors(10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			New("concurrent")
+			RecentErrors()
+		}()
+	}
+	wg.Wait()
+
+	if len(RecentErrors()) != 10 {
+		t.Errorf("Ring should be full")
+	}
+}
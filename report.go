@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Report returns a digest of errs for printing at the end of a batch
+// operation, such as by a command line tool that carries on after errors. It
+// starts with the number of errors, followed by a numbered list with one
+// error per line giving its message and, if it has a stacktrace, the path
+// that the application took to it as returned by AppPath:
+//
+//	2 errors:
+//	1. open config: EOF (at config.go:40 → main.go:8)
+//	2. connection refused
+//
+// Nil entries are skipped.
+func Report(errs []error) string {
+	count := 0
+	for _, err := range errs {
+		if err != nil {
+			count++
+		}
+	}
+
+	buf := bytes.Buffer{}
+	if count == 1 {
+		buf.WriteString("1 error:\n")
+	} else {
+		fmt.Fprintf(&buf, "%d errors:\n", count)
+	}
+
+	n := 0
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		n++
+
+		fmt.Fprintf(&buf, "%d. %s", n, err.Error())
+		var e *Error
+		if As(err, &e) {
+			if path := e.AppPath(); path != "" {
+				fmt.Fprintf(&buf, " (at %s)", path)
+			}
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,32 @@
+package errors
+
+import (
+	"io"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	frames := []StackFrame{
+		{File: "/src/app/config.go", LineNumber: 40, Package: "main", Name: "load"},
+		{File: "/usr/lib/go/src/os/file.go", LineNumber: 12, Package: "os", Name: "Open"},
+		{File: "/src/app/main.go", LineNumber: 8, Package: "main", Name: "main"},
+	}
+	withStack := &Error{Err: io.EOF, prefix: "open config", frames: frames}
+
+	report := Report([]error{withStack, nil, io.ErrUnexpectedEOF, WrapPrefix(withStack, "retry", 0)})
+	expected := "3 errors:\n" +
+		"1. open config: EOF (at config.go:40 → main.go:8)\n" +
+		"2. unexpected EOF\n" +
+		"3. retry: open config: EOF (at config.go:40 → main.go:8)\n"
+	if report != expected {
+		t.Errorf("Wrong report:\n%s", report)
+	}
+
+	if report := Report([]error{nil, io.EOF}); report != "1 error:\n1. EOF\n" {
+		t.Errorf("Wrong report for one error:\n%s", report)
+	}
+
+	if report := Report(nil); report != "0 errors:\n" {
+		t.Errorf("Wrong report for no errors:\n%s", report)
+	}
+}
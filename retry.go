@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"io"
+)
+
+// RetryableSentinels are the errors that Retryable treats as retryable when
+// they appear in an error's chain. It can be extended from an init function,
+// or set with Configure.
+var RetryableSentinels = []error{io.ErrUnexpectedEOF}
+
+// Retryable reports whether the operation that returned err may succeed if it
+// is retried. That is the case if any error in err's chain was marked with
+// MarkRetryable, has a Retryable() bool or Timeout() bool method that returns
+// true, as network timeouts do, or is one of RetryableSentinels.
+func Retryable(err error) bool {
+	retryable := false
+	walk(err, func(err error) bool {
+		switch x := err.(type) {
+		case *Error:
+			retryable = x.retryable
+		case interface{ Retryable() bool }:
+			retryable = x.Retryable()
+		case interface{ Timeout() bool }:
+			retryable = x.Timeout()
+		}
+		return !retryable
+	})
+	if retryable {
+		return true
+	}
+
+	for _, sentinel := range config().RetryableSentinels {
+		if Is(err, sentinel) {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkRetryable returns err marked so that Retryable reports true for it and
+// for any error that wraps it. If err is already an *Error a copy is marked,
+// so shared errors are not modified; otherwise it is wrapped as Wrap does,
+// with a stacktrace that points to the line of code that called
+// MarkRetryable. MarkRetryable returns nil if err is nil.
+func MarkRetryable(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var marked *Error
+	if e, ok := err.(*Error); ok {
+		marked = e.clone()
+	} else {
+		marked = Wrap(err, 1)
+	}
+	marked.retryable = true
+	return marked
+}
@@ -0,0 +1,49 @@
+package errors
+
+import (
+	"io"
+	"testing"
+)
+
+type timeoutError struct{ timeout bool }
+
+func (e timeoutError) Error() string { return "timeout" }
+func (e timeoutError) Timeout() bool { return e.timeout }
+
+func TestRetryable(t *testing.T) {
+	if Retryable(nil) || Retryable(io.EOF) || Retryable(New(io.EOF)) {
+		t.Errorf("Errors are not retryable by default")
+	}
+
+	if !Retryable(WrapPrefix(io.ErrUnexpectedEOF, "read", 0)) {
+		t.Errorf("Sentinels should be retryable when wrapped")
+	}
+
+	if !Retryable(New(wrappingError{"dial", timeoutError{true}})) || Retryable(timeoutError{false}) {
+		t.Errorf("Timeouts should be retryable")
+	}
+
+	marked := MarkRetryable(io.EOF)
+	if !Retryable(marked) || !Retryable(WrapPrefix(marked, "read", 0)) || !Retryable(New(wrappingError{"read", marked})) {
+		t.Errorf("Marked errors should be retryable when wrapped")
+	}
+
+	if frame, _ := marked.TopFrame(); frame.Name != "TestRetryable" {
+		t.Errorf("Stack should start at the call to MarkRetryable: %s", frame.Name)
+	}
+
+	shared := New(io.EOF)
+	if MarkRetryable(shared) == shared || Retryable(shared) {
+		t.Errorf("Marking an *Error should not modify it")
+	}
+
+	if MarkRetryable(nil) != nil {
+		t.Errorf("Marking nil should return nil")
+	}
+
+	defer func(sentinels []error) { RetryableSentinels = sentinels }(RetryableSentinels)
+	RetryableSentinels = append(RetryableSentinels, io.EOF)
+	if !Retryable(New(io.EOF)) {
+		t.Errorf("Added sentinels should be retryable")
+	}
+}
@@ -0,0 +1,30 @@
+package errors
+
+import (
+	"sync"
+)
+
+// SiteSampleRate, when greater than 1, bounds the cost of capturing stacks for
+// errors created on hot paths. The full stack is always captured for the first
+// error created at each call site, so no new error location is missed, and
+// then for one in every SiteSampleRate errors created there. The other errors
+// only record the call site itself. The default of 0 captures every stack.
+//
+// Deprecated: Use Configure.
+var SiteSampleRate = 0
+
+var sites = struct {
+	sync.Mutex
+	counts map[uintptr]int
+}{counts: make(map[uintptr]int)}
+
+// sampleSite counts an error created at the call site identified by pc, and
+// reports whether its full stack should be captured.
+func sampleSite(pc uintptr, rate int) bool {
+	sites.Lock()
+	defer sites.Unlock()
+
+	n := sites.counts[pc]
+	sites.counts[pc] = n + 1
+	return n%rate == 0
+}
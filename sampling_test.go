@@ -0,0 +1,40 @@
+package errors
+
+import (
+	"testing"
+)
+
+func TestSiteSampleRate(t *testing.T) {
+	defer func() { SiteSampleRate = 0 }()
+	SiteSampleRate = 3
+
+	var full, partial int
+	var top uintptr
+	for i := 0; i < 7; i++ {
+		err := New("sampled")
+		if len(err.stack) > 1 {
+			full++
+			top = err.stack[0]
+		} else {
+			partial++
+			if len(err.stack) != 1 || err.stack[0] != top {
+				t.Errorf("Sampled out error should keep its call site")
+			}
+		}
+	}
+
+	if full != 3 || partial != 4 {
+		t.Errorf("Wrong sampling: %d full, %d partial", full, partial)
+	}
+
+	if err := New("other site"); len(err.stack) <= 1 {
+		t.Errorf("First error at a new call site should have a full stack")
+	}
+
+	SiteSampleRate = 0
+	for i := 0; i < 3; i++ {
+		if err := New("unsampled"); len(err.stack) <= 1 {
+			t.Errorf("Every stack should be captured without sampling")
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package errors
+
+import (
+	"sync/atomic"
+)
+
+var (
+	sequenceEnabled int32
+	sequence        uint64
+)
+
+// EnableSequence makes every new error get a process-wide sequence number,
+// which increases with each error created, so that the order in which errors
+// happened can be reconstructed even when their timestamps are the same. It
+// is disabled by default to save the atomic increment on every new error.
+func EnableSequence(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&sequenceEnabled, 1)
+	} else {
+		atomic.StoreInt32(&sequenceEnabled, 0)
+	}
+}
+
+// Seq returns the sequence number the error was given when it was created,
+// or 0 if EnableSequence had not been called.
+func (err *Error) Seq() uint64 {
+	return err.seq
+}
+
+// nextSeq returns the sequence number for a new error.
+func nextSeq() uint64 {
+	if atomic.LoadInt32(&sequenceEnabled) == 0 {
+		return 0
+	}
+	return atomic.AddUint64(&sequence, 1)
+}
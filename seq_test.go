@@ -0,0 +1,24 @@
+package errors
+
+import (
+	"io"
+	"testing"
+)
+
+func TestSeq(t *testing.T) {
+	if New(io.EOF).Seq() != 0 {
+		t.Errorf("Sequence numbers should be disabled by default")
+	}
+
+	EnableSequence(true)
+	defer EnableSequence(false)
+
+	first, second, third := New(io.EOF), Errorf("boom"), Wrap(io.EOF, 0)
+	if first.Seq() == 0 || second.Seq() <= first.Seq() || third.Seq() <= second.Seq() {
+		t.Errorf("Sequence numbers should increase: %d %d %d", first.Seq(), second.Seq(), third.Seq())
+	}
+
+	if WrapPrefix(first, "read", 0).Seq() != first.Seq() {
+		t.Errorf("WrapPrefix should keep the sequence number")
+	}
+}
@@ -0,0 +1,61 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Snapshot returns a representation of err intended for golden files in
+// tests, which stays the same across machines and across edits that only
+// move code within a function. It has the type name and message of err on
+// the first line, then a "key: value" line for each detail attached anywhere
+// in err's chain, sorted by key, then a line for each frame of the stack of
+// the outermost *Error in the chain. Frames are normalized: each is shown as
+// the package path and base name of its file followed by the function name,
+// and line numbers, program counters and call arguments are left out.
+// Snapshot returns an empty string if err is nil.
+func Snapshot(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+
+	var wrapped *Error
+	if As(err, &wrapped) && wrapped == err {
+		buf.WriteString(wrapped.TypeName())
+	} else {
+		fmt.Fprintf(&buf, "%T", err)
+	}
+	buf.WriteString(" " + err.Error() + "\n")
+
+	details := map[string]string{}
+	for _, d := range chainDetails(err) {
+		details[d.key] = detailValue(d.value)
+	}
+	keys := make([]string, 0, len(details))
+	for key := range details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "%s: %s\n", key, details[key])
+	}
+
+	if wrapped != nil {
+		for _, frame := range renderedFrames(wrapped.StackFrames()) {
+			file := frame.File
+			if slash := strings.LastIndex(file, "/"); slash >= 0 {
+				file = file[slash+1:]
+			}
+			if frame.Package != "" {
+				file = frame.Package + "/" + file
+			}
+			fmt.Fprintf(&buf, "%s: %s\n", file, frame.Name)
+		}
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+func snapshotError(early bool) *Error {
+	if early {
+		return New(io.EOF)
+	}
+
+	return New(io.EOF)
+}
+
+func TestSnapshot(t *testing.T) {
+	var snapshots []string
+	for _, early := range []bool{true, false} {
+		snapshots = append(snapshots, Snapshot(snapshotError(early)))
+	}
+
+	if snapshots[0] != snapshots[1] {
+		t.Errorf("Snapshots differ between lines of the same function:\n%s\n%s", snapshots[0], snapshots[1])
+	}
+
+	err := (&Error{Err: io.EOF, frames: []StackFrame{
+		{File: "/home/user/app/handler.go", LineNumber: 12, Name: "handle", Package: "example.com/app"},
+		{File: "/usr/local/go/src/net/http/server.go", LineNumber: 2084, Name: "HandlerFunc.ServeHTTP", Package: "net/http"},
+	}}).WithDetail("user", stringer("bob")).WithDetail("id", stringer("42"))
+
+	expected := "*errors.errorString EOF\n" +
+		"id: 42\n" +
+		"user: bob\n" +
+		"example.com/app/handler.go: handle\n" +
+		"net/http/server.go: HandlerFunc.ServeHTTP\n"
+	if Snapshot(err) != expected {
+		t.Errorf("Wrong snapshot:\n%s", Snapshot(err))
+	}
+
+	if Snapshot(fmt.Errorf("plain")) != "*errors.errorString plain\n" {
+		t.Errorf("Wrong snapshot for a plain error: %q", Snapshot(fmt.Errorf("plain")))
+	}
+
+	if Snapshot(nil) != "" {
+		t.Errorf("Snapshot of nil should be empty")
+	}
+}
@@ -5,10 +5,21 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 )
 
+// RelativePaths makes stack frames display each file relative to the root of
+// its module, as the package path followed by the file name, instead of the
+// absolute path on the machine that built the program. This makes rendered
+// stacks identical across machines, e.g. for golden files in tests. It only
+// changes how frames are displayed; the File field is not modified.
+//
+// Deprecated: Use Configure.
+var RelativePaths = false
+
 // A StackFrame contains all necessary information about to generate a line
 // in a callstack.
 type StackFrame struct {
@@ -22,36 +33,83 @@ type StackFrame struct {
 	Package string
 	// The underlying ProgramCounter
 	ProgramCounter uintptr
+
+	// The arguments of the call, as printed in a panic's stacktrace
+	args string
 }
 
 // NewStackFrame popoulates a stack frame object from the program counter.
 func NewStackFrame(pc uintptr) (frame StackFrame) {
+	return newStackFrame(pc, true)
+}
+
+// MakeStackFrame makes a stack frame from explicit values rather than from a
+// program counter, e.g. for test fixtures or frames read from a log. The
+// frame has no ProgramCounter, so its Func is nil.
+func MakeStackFrame(file string, line int, name, pkg string) StackFrame {
+	return StackFrame{
+		File:       file,
+		LineNumber: line,
+		Name:       name,
+		Package:    pkg,
+	}
+}
+
+// newStackFrame populates a stack frame object from the program counter,
+// which is a return address unless the frame was interrupted by a signal
+// (e.g. a nil pointer dereference), in which case pc is the faulting
+// instruction.
+func newStackFrame(pc uintptr, returnAddress bool) (frame StackFrame) {
 
 	frame = StackFrame{ProgramCounter: pc}
-	if frame.Func() == nil {
+	if pc == 0 {
 		return
 	}
-	frame.Package, frame.Name = packageAndName(frame.Func())
 
 	// pc -1 because the program counters we use are usually return addresses,
-	// and we want to show the line that corresponds to the function call
-	frame.File, frame.LineNumber = frame.Func().FileLine(pc - 1)
+	// and we want to show the line that corresponds to the function call. The
+	// function must be looked up at the same pc as the line, as the return
+	// address of an inlined call may already be outside the inlined function.
+	lookup := pc
+	if returnAddress {
+		lookup--
+	}
+
+	fn := runtime.FuncForPC(lookup)
+	if fn == nil {
+		return
+	}
+	frame.Package, frame.Name = packageAndName(fn)
+	frame.File, frame.LineNumber = fn.FileLine(lookup)
 	return
 
 }
 
+// stackFrameFromRuntime converts a frame that was resolved by
+// runtime.CallersFrames into a StackFrame.
+func stackFrameFromRuntime(f runtime.Frame) StackFrame {
+	frame := StackFrame{
+		File:           f.File,
+		LineNumber:     f.Line,
+		ProgramCounter: f.PC,
+	}
+	frame.Package, frame.Name = splitFuncName(f.Function)
+	return frame
+}
+
 // Func returns the function that contained this frame.
 func (frame *StackFrame) Func() *runtime.Func {
 	if frame.ProgramCounter == 0 {
 		return nil
 	}
-	return runtime.FuncForPC(frame.ProgramCounter)
+	// pc -1 for the same reason as in NewStackFrame.
+	return runtime.FuncForPC(frame.ProgramCounter - 1)
 }
 
 // String returns the stackframe formatted in the same way as go does
 // in runtime/debug.Stack()
 func (frame *StackFrame) String() string {
-	str := fmt.Sprintf("%s:%d (0x%x)\n", frame.File, frame.LineNumber, frame.ProgramCounter)
+	str := fmt.Sprintf("%s:%d (0x%x)\n", frame.displayFile(), frame.LineNumber, frame.ProgramCounter)
 
 	source, err := frame.sourceLine()
 	if err != nil {
@@ -61,6 +119,83 @@ func (frame *StackFrame) String() string {
 	return str + fmt.Sprintf("\t%s: %s\n", frame.Name, source)
 }
 
+// Short returns a compact description of the frame as the base name of its
+// file, its line number and its function name, e.g. "error.go:42:New".
+func (frame *StackFrame) Short() string {
+	return fmt.Sprintf("%s:%d:%s", filepath.Base(frame.File), frame.LineNumber, frame.Name)
+}
+
+// Args returns the arguments of the call as printed in the stacktrace of a
+// panic, e.g. "0x35ce40, 0xc208039db0", for frames parsed by ParsePanic. It
+// is empty for frames captured by this package, as their arguments are not
+// recorded.
+func (frame *StackFrame) Args() string {
+	return frame.args
+}
+
+// displayFile returns the file name to display for this frame, taking
+// RelativePaths into account.
+func (frame *StackFrame) displayFile() string {
+	if !config().RelativePaths || frame.Package == "" {
+		return frame.File
+	}
+
+	name := frame.File
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+	return frame.Package + "/" + name
+}
+
+// InApp reports whether the frame belongs to the application rather than to
+// the standard library or a dependency. A frame is in the application if its
+// package is main or is part of the main module, as reported by
+// runtime/debug.ReadBuildInfo, and is not vendored.
+func (frame *StackFrame) InApp() bool {
+	if frame.Package == "main" {
+		return true
+	}
+
+	module := mainModule()
+	if module == "" {
+		return false
+	}
+	if frame.Package != module && !strings.HasPrefix(frame.Package, module+"/") {
+		return false
+	}
+	return !strings.Contains(frame.Package[len(module):]+"/", "/vendor/")
+}
+
+// IsRuntime reports whether the frame is in the runtime package.
+func (frame *StackFrame) IsRuntime() bool {
+	return frame.Package == "runtime"
+}
+
+// IsStdlib reports whether the frame is in the standard library. Standard
+// library packages are told apart from other packages by the absence of a
+// dot in the first element of their path.
+func (frame *StackFrame) IsStdlib() bool {
+	if frame.Package == "" || frame.Package == "main" {
+		return false
+	}
+
+	first := frame.Package
+	if slash := strings.Index(first, "/"); slash >= 0 {
+		first = first[:slash]
+	}
+	return !strings.Contains(first, ".")
+}
+
+var mainModuleOnce sync.Once
+var mainModulePath string
+
+func mainModule() string {
+	mainModuleOnce.Do(func() {
+		mainModulePath = readMainModule()
+	})
+	return mainModulePath
+}
+
 // SourceLine gets the line of code (from File and Line) of the original source if possible.
 func (frame *StackFrame) SourceLine() (string, error) {
 	source, err := frame.sourceLine()
@@ -70,12 +205,56 @@ func (frame *StackFrame) SourceLine() (string, error) {
 	return source, err
 }
 
+// sourceLineEntry is a line of source code cached for a program counter.
+type sourceLineEntry struct {
+	file   string
+	line   int
+	source string
+}
+
+// sources caches the lines of source code read by sourceLine, so an error
+// that is rendered many times, e.g. on a retry path, only reads each file
+// once. It is keyed by program counter and only holds lines that were read
+// successfully for frames whose program counter is in this program, so its
+// size is bounded by the program's code however many frames with other
+// files, such as from ParsePanic or UnmarshalJSON, are rendered.
+var sources = struct {
+	sync.Mutex
+	lines map[uintptr]sourceLineEntry
+}{lines: make(map[uintptr]sourceLineEntry)}
+
+// openSource opens a source file for sourceLine. It is replaced in tests.
+var openSource = os.Open
+
 func (frame *StackFrame) sourceLine() (string, error) {
 	if frame.LineNumber <= 0 {
 		return "???", nil
 	}
 
-	file, err := os.Open(frame.File)
+	pc := frame.ProgramCounter
+	cacheable := pc != 0 && runtime.FuncForPC(pc) != nil
+	if cacheable {
+		sources.Lock()
+		cached, ok := sources.lines[pc]
+		sources.Unlock()
+		if ok && cached.file == frame.File && cached.line == frame.LineNumber {
+			return cached.source, nil
+		}
+	}
+
+	source, err := readSourceLine(frame.File, frame.LineNumber)
+
+	if cacheable && err == nil {
+		sources.Lock()
+		sources.lines[pc] = sourceLineEntry{frame.File, frame.LineNumber, source}
+		sources.Unlock()
+	}
+
+	return source, err
+}
+
+func readSourceLine(name string, line int) (string, error) {
+	file, err := openSource(name)
 	if err != nil {
 		return "", err
 	}
@@ -84,7 +263,7 @@ func (frame *StackFrame) sourceLine() (string, error) {
 	scanner := bufio.NewScanner(file)
 	currentLine := 1
 	for scanner.Scan() {
-		if currentLine == frame.LineNumber {
+		if currentLine == line {
 			return string(bytes.Trim(scanner.Bytes(), " \t")), nil
 		}
 		currentLine++
@@ -97,7 +276,13 @@ func (frame *StackFrame) sourceLine() (string, error) {
 }
 
 func packageAndName(fn *runtime.Func) (string, string) {
-	name := fn.Name()
+	return splitFuncName(fn.Name())
+}
+
+// splitFuncName splits a fully qualified function name, as reported by
+// runtime.Func.Name or runtime.Frame.Function, into its package path and
+// its name within that package.
+func splitFuncName(name string) (string, string) {
 	pkg := ""
 
 	// The name includes the path name to the package, which is unnecessary
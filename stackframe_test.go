@@ -0,0 +1,265 @@
+package errors
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func BenchmarkRenderSameError(b *testing.B) {
+	b.ReportAllocs()
+	err := New("foo")
+
+	for i := 0; i < b.N; i++ {
+		_ = err.ErrorStack()
+	}
+}
+
+func TestRelativePaths(t *testing.T) {
+	defer func() { RelativePaths = false }()
+
+	frame := New("foo").StackFrames()[0]
+
+	if !strings.HasPrefix(frame.String(), frame.File+":") {
+		t.Errorf("Frame should use the absolute path by default: %s", frame.String())
+	}
+
+	RelativePaths = true
+	if !strings.HasPrefix(frame.String(), "github.com/go-errors/errors/stackframe_test.go:") {
+		t.Errorf("Frame should use the package relative path: %s", frame.String())
+	}
+
+	if !strings.HasSuffix(frame.File, "/stackframe_test.go") || strings.HasPrefix(frame.File, "github.com/") {
+		t.Errorf("RelativePaths should not change File: %s", frame.File)
+	}
+}
+
+func TestInApp(t *testing.T) {
+	frames := New("foo").StackFrames()
+
+	if !frames[0].InApp() {
+		t.Errorf("Frame in this module should be in the app: %s", frames[0].Package)
+	}
+
+	if last := frames[len(frames)-1]; last.InApp() {
+		t.Errorf("Runtime frame should not be in the app: %s", last.Package)
+	}
+
+	for _, pkg := range []string{"main", "github.com/go-errors/errors/internal"} {
+		if frame := (StackFrame{Package: pkg}); !frame.InApp() {
+			t.Errorf("%s should be in the app", pkg)
+		}
+	}
+
+	for _, pkg := range []string{"fmt", "github.com/go-errors/errorsx", "github.com/go-errors/errors/vendor/example.com/dep"} {
+		if frame := (StackFrame{Package: pkg}); frame.InApp() {
+			t.Errorf("%s should not be in the app", pkg)
+		}
+	}
+}
+
+func TestIsRuntimeAndStdlib(t *testing.T) {
+	frames := New("foo").StackFrames()
+	if frames[0].IsRuntime() || frames[0].IsStdlib() {
+		t.Errorf("Frame in this package should not be stdlib")
+	}
+
+	if last := frames[len(frames)-1]; !last.IsRuntime() || !last.IsStdlib() {
+		t.Errorf("Last frame should be in the runtime: %s", last.Package)
+	}
+
+	for _, pkg := range []string{"net/http", "testing", "runtime/debug"} {
+		if frame := (StackFrame{Package: pkg}); !frame.IsStdlib() || frame.IsRuntime() {
+			t.Errorf("%s should be stdlib but not runtime", pkg)
+		}
+	}
+
+	for _, pkg := range []string{"", "main", "example.com/http", "gopkg.in/yaml.v2"} {
+		if frame := (StackFrame{Package: pkg}); frame.IsStdlib() {
+			t.Errorf("%q should not be stdlib", pkg)
+		}
+	}
+}
+
+func TestShort(t *testing.T) {
+	frame := StackFrame{File: "/home/user/src/example.com/app/internal/db/query.go", LineNumber: 42, Name: "(*Conn).Query"}
+	if frame.Short() != "query.go:42:(*Conn).Query" {
+		t.Errorf("Wrong short form: %s", frame.Short())
+	}
+
+	err := New("foo")
+	lines := strings.Split(err.CompactStack(), "\n")
+	if len(lines) != len(err.StackFrames())+1 || !strings.HasPrefix(lines[0], "stackframe_test.go:") || !strings.HasSuffix(lines[0], ":TestShort") {
+		t.Errorf("Wrong compact stack: %s", err.CompactStack())
+	}
+}
+
+type nilPointer struct{ field int }
+
+//go:noinline
+func dereference(p *nilPointer) int {
+	return p.field
+}
+
+func TestFramesMatchRuntime(t *testing.T) {
+	var err *Error
+	func() {
+		defer func() {
+			err = Wrap(recover(), 0)
+		}()
+		dereference(nil)
+	}()
+
+	frames, expected := err.StackFrames(), callersToFrames(err.stack)
+	if len(frames) != len(expected) {
+		t.Fatalf("Wrong number of frames: %d != %d", len(frames), len(expected))
+	}
+
+	sawDereference := false
+	for i, frame := range frames {
+		if frame.Package+"."+frame.Name != expected[i].Function || frame.File != expected[i].File || frame.LineNumber != expected[i].Line {
+			t.Errorf("Frame %d is %s.%s at %s:%d, runtime says %s at %s:%d", i, frame.Package, frame.Name, frame.File, frame.LineNumber, expected[i].Function, expected[i].File, expected[i].Line)
+		}
+		if frame.Name == "dereference" {
+			sawDereference = true
+			if source, _ := frame.SourceLine(); source != "return p.field" {
+				t.Errorf("Faulting frame points at the wrong line: %s", source)
+			}
+		}
+	}
+
+	if !sawDereference {
+		t.Errorf("Stack does not contain the faulting frame")
+	}
+}
+
+//go:noinline
+func levelOne() *Error {
+	return levelTwo() // levelOne calls levelTwo
+}
+
+//go:noinline
+func levelTwo() *Error {
+	err := levelThree() // levelTwo calls levelThree
+	return err
+}
+
+//go:noinline
+func levelThree() *Error {
+	return New("deep") // levelThree calls New
+}
+
+func TestFrameLinesAreCallLines(t *testing.T) {
+	frames := levelOne().StackFrames()
+
+	for i, name := range []string{"levelThree", "levelTwo", "levelOne"} {
+		if frames[i].Name != name {
+			t.Fatalf("Frame %d is %s, expected %s", i, frames[i].Name, name)
+		}
+
+		source, err := frames[i].SourceLine()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(source, "// "+name+" calls "+[]string{"New", "levelThree", "levelTwo"}[i]) {
+			t.Errorf("Frame %d points at the wrong line: %s", i, source)
+		}
+
+		if frames[i].Func() == nil || frames[i].Func().Name() != "github.com/go-errors/errors."+name {
+			t.Errorf("Frame %d has the wrong function", i)
+		}
+	}
+
+	if source, _ := frames[3].SourceLine(); source != "frames := levelOne().StackFrames()" {
+		t.Errorf("Caller frame points at the wrong line: %s", source)
+	}
+}
+
+func TestMakeStackFrame(t *testing.T) {
+	frame := MakeStackFrame("/src/example.com/app/missing.go", 12, "(*Server).Run", "example.com/app")
+
+	if frame.String() != "/src/example.com/app/missing.go:12 (0x0)\n" {
+		t.Errorf("Wrong rendering without source: %q", frame.String())
+	}
+
+	if frame.Func() != nil || frame.ProgramCounter != 0 || frame.Short() != "missing.go:12:(*Server).Run" {
+		t.Errorf("Frame without a program counter was not handled")
+	}
+
+	real := New("foo").StackFrames()[0]
+	frame = MakeStackFrame(real.File, real.LineNumber, real.Name, real.Package)
+	if !strings.HasPrefix(frame.String(), fmt.Sprintf("%s:%d (0x0)\n", real.File, real.LineNumber)) || !strings.HasSuffix(frame.String(), "\tTestMakeStackFrame: real := New(\"foo\").StackFrames()[0]\n") {
+		t.Errorf("Wrong rendering with source: %q", frame.String())
+	}
+}
+
+func TestSourceLineCached(t *testing.T) {
+	defer func() { openSource = os.Open }()
+
+	opens := 0
+	openSource = func(name string) (*os.File, error) {
+		opens++
+		return os.Open(name)
+	}
+
+	frame := New("foo").StackFrames()[0]
+	for i := 0; i < 3; i++ {
+		if source, _ := frame.SourceLine(); source != `frame := New("foo").StackFrames()[0]` {
+			t.Errorf("Wrong source line: %s", source)
+		}
+	}
+
+	if opens != 1 {
+		t.Errorf("Source file was opened %d times", opens)
+	}
+}
+
+func TestSourceLineNotCachedForSyntheticFrames(t *testing.T) {
+	sources.Lock()
+	before := len(sources.lines)
+	sources.Unlock()
+
+	for i := 0; i < 10; i++ {
+		frame := MakeStackFrame(fmt.Sprintf("/does/not/exist/%d.go", i), 1, "f", "main")
+		if _, err := frame.SourceLine(); err == nil {
+			t.Errorf("Missing file should fail")
+		}
+	}
+
+	decoded := new(Error)
+	if err := decoded.UnmarshalJSON(mustMarshal(t, New("foo"))); err != nil {
+		t.Fatal(err)
+	}
+	for _, frame := range decoded.StackFrames() {
+		frame.SourceLine()
+	}
+
+	sources.Lock()
+	after := len(sources.lines)
+	sources.Unlock()
+	if after != before {
+		t.Errorf("Synthetic frames should not be cached: %d before, %d after", before, after)
+	}
+}
+
+func TestSourceLineErrorNotCached(t *testing.T) {
+	defer func() { openSource = os.Open }()
+
+	opens := 0
+	openSource = func(name string) (*os.File, error) {
+		opens++
+		return nil, os.ErrNotExist
+	}
+
+	frame := New("foo").StackFrames()[0]
+	for i := 0; i < 2; i++ {
+		if _, err := frame.SourceLine(); err == nil {
+			t.Errorf("Failed read should return an error")
+		}
+	}
+
+	if opens != 2 {
+		t.Errorf("Failed reads should not be cached: %d opens", opens)
+	}
+}
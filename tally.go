@@ -0,0 +1,61 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A CountedError is an error that occurred Count times, as returned by Tally.
+type CountedError struct {
+	// Err is the first of the errors that were counted together.
+	Err   error
+	Count int
+}
+
+// Tally collapses errs into one entry per distinct error with the number of
+// times it occurred, for summaries such as "this failed 347 times". Errors
+// are counted together if the outermost *Error in their chains were created
+// at the same place, that is if their stacks have the same frames, so that
+// messages that contain varying data such as IDs are still grouped; errors
+// without a stack are counted together if they have the same message. The
+// result is ordered by descending count, and errors with the same count by
+// their first occurrence. Nil entries are skipped.
+func Tally(errs []error) []CountedError {
+	var tallied []CountedError
+	index := map[string]int{}
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		key := tallyKey(err)
+		if i, ok := index[key]; ok {
+			tallied[i].Count++
+			continue
+		}
+		index[key] = len(tallied)
+		tallied = append(tallied, CountedError{Err: err, Count: 1})
+	}
+
+	sort.SliceStable(tallied, func(i, j int) bool {
+		return tallied[i].Count > tallied[j].Count
+	})
+	return tallied
+}
+
+// tallyKey returns the key that Tally groups err by.
+func tallyKey(err error) string {
+	var e *Error
+	if As(err, &e) {
+		if frames := e.StackFrames(); len(frames) > 0 {
+			keys := make([]string, len(frames))
+			for i, frame := range frames {
+				keys[i] = fmt.Sprintf("%s.%s %s:%d", frame.Package, frame.Name, frame.File, frame.LineNumber)
+			}
+			return "stack\n" + strings.Join(keys, "\n")
+		}
+	}
+	return "message\n" + err.Error()
+}
@@ -0,0 +1,36 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestTally(t *testing.T) {
+	var errs []error
+	for i := 0; i < 3; i++ {
+		errs = append(errs, Errorf("user %d not found", i))
+	}
+	errs = append(errs, nil, io.EOF, fmt.Errorf("EOF"), io.ErrUnexpectedEOF)
+
+	tallied := Tally(errs)
+	if len(tallied) != 3 {
+		t.Fatalf("Wrong number of entries: %v", tallied)
+	}
+
+	if tallied[0].Count != 3 || tallied[0].Err != errs[0] {
+		t.Errorf("Errors from the same place should be counted together: %v", tallied[0])
+	}
+
+	if tallied[1].Count != 2 || tallied[1].Err != io.EOF {
+		t.Errorf("Errors without a stack should be counted by message: %v", tallied[1])
+	}
+
+	if tallied[2].Count != 1 || tallied[2].Err != io.ErrUnexpectedEOF {
+		t.Errorf("Wrong last entry: %v", tallied[2])
+	}
+
+	if len(Tally(nil)) != 0 {
+		t.Errorf("Tally of no errors should be empty")
+	}
+}
@@ -0,0 +1,20 @@
+package errors
+
+// WithTraceID returns a copy of the error with the given trace or correlation
+// ID attached, so that tracing and logging integrations can connect the
+// error to the request that caused it. The ID is kept when the error is
+// wrapped further and can be read with TraceID.
+func (err *Error) WithTraceID(id string) *Error {
+	traced := err.clone()
+	traced.traceID = id
+	return traced
+}
+
+// TraceID returns the trace ID of err, which is the one closest to the top of
+// its chain if several were attached with WithTraceID, or "" if none was.
+func TraceID(err error) string {
+	if traced := outermost(err, func(e *Error) bool { return e.traceID != "" }); traced != nil {
+		return traced.traceID
+	}
+	return ""
+}
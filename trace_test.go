@@ -0,0 +1,28 @@
+package errors
+
+import (
+	"io"
+	"testing"
+)
+
+func TestTraceID(t *testing.T) {
+	base := New(io.EOF)
+	traced := base.WithTraceID("4bf92f3577b34da6")
+
+	if TraceID(base) != "" {
+		t.Errorf("WithTraceID modified the original error")
+	}
+
+	err := New(wrappingError{"handler", WrapPrefix(traced, "load", 0)})
+	if TraceID(err) != "4bf92f3577b34da6" {
+		t.Errorf("Trace ID was lost when wrapping: %q", TraceID(err))
+	}
+
+	if TraceID(err.WithTraceID("outer")) != "outer" {
+		t.Errorf("Outermost trace ID should win")
+	}
+
+	if TraceID(io.EOF) != "" || TraceID(nil) != "" {
+		t.Errorf("Errors without a trace ID should have none")
+	}
+}